@@ -5,18 +5,13 @@ import (
 )
 
 func HasPrefixCommand(message string, prefixes []string) (string, bool) {
+	message = strings.TrimLeft(message, " \t\n　")
 	for _, prefix := range prefixes {
-		if strings.HasPrefix(message, prefix) {
-			return prefix, true
+		for _, variant := range []string{prefix, strings.Replace(prefix, ":", "：", -1)} {
+			if len(message) >= len(variant) && strings.EqualFold(message[:len(variant)], variant) {
+				return message[:len(variant)], true
+			}
 		}
 	}
 	return "", false
 }
-
-func IsNormalCommand(cmd []string) bool {
-	return len(cmd) == 2
-}
-
-func IsSettingCommand(cmd []string) bool {
-	return len(cmd) == 3
-}
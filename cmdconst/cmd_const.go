@@ -1,5 +1,11 @@
 package cmdconst
 
+import (
+	"strings"
+
+	"../i18n"
+)
+
 const (
 	// Normal commands
 	NORMAL_HELP            = "ヘルプ"
@@ -10,10 +16,195 @@ const (
 	NORMAL_LEAVEBOTS       = "退会"
 	NORMAL_CHANGESUBADMIN  = "サブ管理者変更"
 
+	NORMAL_KICK      = "キック"
+	NORMAL_BAN       = "バン"
+	NORMAL_UNBAN     = "バン解除"
+	NORMAL_PROTECT   = "保護"
+	NORMAL_UNPROTECT = "保護解除"
+	NORMAL_PROMOTE   = "昇格"
+
 	// Setting commands
 	SETTING_NAME   = "グループ名ロック"
 	SETTING_ICON   = "アイコンロック"
 	SETTING_URL    = "招待リンク拒否"
 	SETTING_INVITE = "招待拒否"
 	SETTING_CHECK  = "確認"
+
+	SETTING_ADDTRUSTEDINVITER    = "信頼招待者追加"
+	SETTING_REMOVETRUSTEDINVITER = "信頼招待者削除"
+	SETTING_CHECKTRUSTEDINVITERS = "信頼招待者確認"
+
+	SETTING_LOCKDOWN   = "ロックダウン"
+	SETTING_UNLOCKDOWN = "ロックダウン解除"
+
+	SETTING_ADDSCHEDULE    = "スケジュール追加"
+	SETTING_REMOVESCHEDULE = "スケジュール削除"
+
+	SETTING_PRESET    = "プリセット"
+	SETTING_TEMPLATES = "テンプレート一覧"
+	SETTING_COPY      = "コピー"
+
+	SETTING_LANGUAGE = "言語"
+
+	SETTING_BULK = "一括"
+
+	SETTING_ADDPREFIX     = "プレフィックス追加"
+	SETTING_REMOVEPREFIX  = "プレフィックス削除"
+	SETTING_CHECKPREFIXES = "プレフィックス確認"
+	SETTING_REPLACEPREFIX = "プレフィックス置換"
+
+	// Direct commands
+	DIRECT_GROUPS   = "グループ一覧"
+	DIRECT_SELECT   = "グループ選択"
+	DIRECT_DESELECT = "選択解除"
+
+	DIRECT_ISSUETICKETS = "チケット発行"
+	DIRECT_LISTTICKETS  = "チケット一覧"
+
+	// Admin commands
+	ADMIN_GRANT       = "権限付与"
+	ADMIN_EXTEND      = "権限延長"
+	ADMIN_REVOKE      = "権限剥奪"
+	ADMIN_LOOKUP      = "ユーザー確認"
+	ADMIN_GROUPS      = "全グループ"
+	ADMIN_FLEET       = "稼働状況"
+	ADMIN_ADDADMIN    = "管理者追加"
+	ADMIN_REMOVEADMIN = "管理者削除"
+	ADMIN_LISTADMINS  = "管理者一覧"
+	ADMIN_BROADCAST   = "一斉送信"
+	ADMIN_JOBS        = "ジョブ一覧"
+	ADMIN_RUNJOB      = "ジョブ実行"
+	ADMIN_JOBHISTORY  = "ジョブ履歴"
+	ADMIN_CLEANUP     = "掃除確認"
+	ADMIN_SETPLAN     = "プラン設定"
+
+	// Presets
+	PRESET_STANDARD = "標準"
+	PRESET_STRICT   = "厳重"
+	PRESET_OPEN     = "開放"
+
+	// Prefix families
+	PREFIXFAMILY_NORMAL  = "通常"
+	PREFIXFAMILY_SETTING = "設定"
+
+	// Ticket kinds
+	TICKETKIND_WEEK      = "7日"
+	TICKETKIND_MONTH     = "30日"
+	TICKETKIND_PERMANENT = "永久"
+	TICKETKIND_TRIAL     = "体験"
+
+	// Plans
+	PLAN_BASIC   = "ベーシック"
+	PLAN_PRO     = "プロ"
+	PLAN_PREMIUM = "プレミアム"
+
+	// Broadcast filters
+	BROADCAST_ALL    = "全て"
+	BROADCAST_EXPIRY = "期限"
 )
+
+var ENGLISH_NAMES = map[string]string{
+	NORMAL_HELP:            "help",
+	NORMAL_CHECKPERMISSION: "permission",
+	NORMAL_CHECKKICKERS:    "kickers",
+	NORMAL_CHECKSPEED:      "speed",
+	NORMAL_CHECKSTATUS:     "status",
+	NORMAL_LEAVEBOTS:       "leave",
+	NORMAL_CHANGESUBADMIN:  "subadmin",
+
+	NORMAL_KICK:      "kick",
+	NORMAL_BAN:       "ban",
+	NORMAL_UNBAN:     "unban",
+	NORMAL_PROTECT:   "protect",
+	NORMAL_UNPROTECT: "unprotect",
+	NORMAL_PROMOTE:   "promote",
+
+	SETTING_NAME:   "namelock",
+	SETTING_ICON:   "iconlock",
+	SETTING_URL:    "urllock",
+	SETTING_INVITE: "invitelock",
+	SETTING_CHECK:  "check",
+
+	SETTING_ADDTRUSTEDINVITER:    "addtrusted",
+	SETTING_REMOVETRUSTEDINVITER: "removetrusted",
+	SETTING_CHECKTRUSTEDINVITERS: "trusted",
+
+	SETTING_LOCKDOWN:   "lockdown",
+	SETTING_UNLOCKDOWN: "unlockdown",
+
+	SETTING_ADDSCHEDULE:    "addschedule",
+	SETTING_REMOVESCHEDULE: "removeschedule",
+
+	SETTING_PRESET:    "preset",
+	SETTING_TEMPLATES: "templates",
+	SETTING_COPY:      "copy",
+
+	SETTING_LANGUAGE: "language",
+
+	SETTING_BULK: "bulk",
+
+	SETTING_ADDPREFIX:     "addprefix",
+	SETTING_REMOVEPREFIX:  "removeprefix",
+	SETTING_CHECKPREFIXES: "prefixes",
+	SETTING_REPLACEPREFIX: "replaceprefix",
+
+	DIRECT_GROUPS:   "groups",
+	DIRECT_SELECT:   "select",
+	DIRECT_DESELECT: "deselect",
+
+	DIRECT_ISSUETICKETS: "issue",
+	DIRECT_LISTTICKETS:  "tickets",
+
+	ADMIN_GRANT:       "grant",
+	ADMIN_EXTEND:      "extend",
+	ADMIN_REVOKE:      "revoke",
+	ADMIN_LOOKUP:      "user",
+	ADMIN_GROUPS:      "allgroups",
+	ADMIN_FLEET:       "fleet",
+	ADMIN_ADDADMIN:    "addadmin",
+	ADMIN_REMOVEADMIN: "removeadmin",
+	ADMIN_LISTADMINS:  "admins",
+	ADMIN_BROADCAST:   "broadcast",
+	ADMIN_JOBS:        "jobs",
+	ADMIN_RUNJOB:      "runjob",
+	ADMIN_JOBHISTORY:  "jobhistory",
+	ADMIN_CLEANUP:     "cleanup",
+	ADMIN_SETPLAN:     "setplan",
+
+	PRESET_STANDARD: "standard",
+	PRESET_STRICT:   "strict",
+	PRESET_OPEN:     "open",
+
+	PREFIXFAMILY_NORMAL:  "normal",
+	PREFIXFAMILY_SETTING: "setting",
+
+	TICKETKIND_WEEK:      "7d",
+	TICKETKIND_MONTH:     "30d",
+	TICKETKIND_PERMANENT: "permanent",
+	TICKETKIND_TRIAL:     "trial",
+
+	PLAN_BASIC:   "basic",
+	PLAN_PRO:     "pro",
+	PLAN_PREMIUM: "premium",
+
+	BROADCAST_ALL:    "all",
+	BROADCAST_EXPIRY: "expiry",
+}
+
+func Resolve(name string) string {
+	for japanese, english := range ENGLISH_NAMES {
+		if strings.EqualFold(name, english) {
+			return japanese
+		}
+	}
+	return name
+}
+
+func LocalName(language string, name string) string {
+	if language == i18n.LANG_EN {
+		if english, ok := ENGLISH_NAMES[name]; ok {
+			return english
+		}
+	}
+	return name
+}
@@ -2,15 +2,138 @@ package cmdparser
 
 import (
 	"strings"
+	"unicode"
+
+	"../i18n"
 )
 
-func ParsePhrases(message string, prefix string) []string {
-	splitedMessage := strings.SplitN(message, prefix, 2)
-	phrases := strings.Split(splitedMessage[1], ":")
-	phrases, phrases[0] = append(phrases[0:1], phrases[0:]...), splitedMessage[0]
-	return phrases
+type Command struct {
+	Prefix string
+	Name   string
+	Args   []string
+}
+
+type ParseError struct {
+	Pos    int
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return e.Localize(i18n.DEFAULT_LANGUAGE)
+}
+
+func (e *ParseError) Localize(language string) string {
+	return i18n.T(language, "parse.error", e.Pos+1, i18n.T(language, e.Reason))
+}
+
+var quotePairs = map[rune]rune{
+	'"': '"',
+	'「': '」',
+	'“': '”',
+}
+
+func isSeparator(r rune) bool {
+	return r == ':' || r == '：'
+}
+
+func Parse(message string, prefix string) (*Command, error) {
+	text := []rune(message)
+	pos := 0
+	for pos < len(text) && unicode.IsSpace(text[pos]) {
+		pos++
+	}
+	if !strings.HasPrefix(string(text[pos:]), prefix) {
+		return nil, &ParseError{pos, "parse.error.prefix"}
+	}
+	pos += len([]rune(prefix))
+
+	tokens, err := tokenize(text, pos)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0] == "" {
+		return nil, &ParseError{pos, "parse.error.name"}
+	}
+	return &Command{prefix, tokens[0], tokens[1:]}, nil
+}
+
+func PeekName(message string, prefix string) string {
+	text := strings.TrimLeftFunc(message, unicode.IsSpace)
+	if !strings.HasPrefix(text, prefix) {
+		return ""
+	}
+	text = text[len(prefix):]
+	if i := strings.IndexFunc(text, isSeparator); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+func tokenize(text []rune, pos int) ([]string, error) {
+	tokens := []string{}
+	for {
+		start := pos
+		token, quoted, next, err := readToken(text, pos)
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) > 0 && token == "" && !quoted {
+			return nil, &ParseError{start, "parse.error.empty"}
+		}
+		tokens = append(tokens, token)
+		if next >= len(text) {
+			return tokens, nil
+		}
+		pos = next + 1
+	}
+}
+
+func readToken(text []rune, pos int) (string, bool, int, error) {
+	for pos < len(text) && unicode.IsSpace(text[pos]) {
+		pos++
+	}
+	if pos < len(text) {
+		if closing, ok := quotePairs[text[pos]]; ok {
+			token, next, err := readQuoted(text, pos, closing)
+			return token, true, next, err
+		}
+	}
+	start := pos
+	for pos < len(text) && !isSeparator(text[pos]) {
+		if _, ok := quotePairs[text[pos]]; ok {
+			return "", false, 0, &ParseError{pos, "parse.error.quote"}
+		}
+		pos++
+	}
+	return strings.TrimSpace(string(text[start:pos])), false, pos, nil
 }
 
-func ParseCommand(phrases []string) string {
-	return phrases[1]
+func readQuoted(text []rune, pos int, closing rune) (string, int, error) {
+	open := pos
+	pos++
+	var b strings.Builder
+	for {
+		if pos >= len(text) {
+			return "", 0, &ParseError{open, "parse.error.unclosed"}
+		}
+		r := text[pos]
+		if r == '\\' && closing == '"' && pos+1 < len(text) {
+			b.WriteRune(text[pos+1])
+			pos += 2
+			continue
+		}
+		if r == closing {
+			pos++
+			break
+		}
+		b.WriteRune(r)
+		pos++
+	}
+	for pos < len(text) && unicode.IsSpace(text[pos]) {
+		pos++
+	}
+	if pos < len(text) && !isSeparator(text[pos]) {
+		return "", 0, &ParseError{pos, "parse.error.separator"}
+	}
+	return b.String(), pos, nil
 }
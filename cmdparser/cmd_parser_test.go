@@ -0,0 +1,159 @@
+package cmdparser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testPrefix = "tamaki:"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		command string
+		args    []string
+	}{
+		{"name only", "tamaki:help", "help", []string{}},
+		{"full-width separator", "tamaki:言語：en", "言語", []string{"en"}},
+		{"mixed separators", "tamaki:a：b:c", "a", []string{"b", "c"}},
+		{"surrounding whitespace", "  tamaki: kick : a : b  ", "kick", []string{"a", "b"}},
+		{"full-width whitespace", "　tamaki:kick　:　a　", "kick", []string{"a"}},
+		{"inner whitespace", "tamaki:broadcast:all:hello world", "broadcast", []string{"all", "hello world"}},
+		{"multiple arguments", "tamaki:addschedule:invite:22:00-07:00", "addschedule", []string{"invite", "22", "00-07", "00"}},
+		{"quoted separator", `tamaki:addschedule:invite:"22:00-07:00"`, "addschedule", []string{"invite", "22:00-07:00"}},
+		{"quoted escapes", `tamaki:text:"a \"b\" \\ c"`, "text", []string{`a "b" \ c`}},
+		{"japanese quotes", "tamaki:text:「a:b」", "text", []string{"a:b"}},
+		{"curly quotes", "tamaki:text:“a:b”", "text", []string{"a:b"}},
+		{"quoted whitespace", `tamaki:text: "a b" : c`, "text", []string{"a b", "c"}},
+		{"empty quoted argument", `tamaki:text:""`, "text", []string{""}},
+		{"backslash outside double quotes", `tamaki:text:「a\」`, "text", []string{`a\`}},
+		{"quoted rest", `tamaki:broadcast:all:"maintenance: 22:00"`, "broadcast", []string{"all", "maintenance: 22:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := Parse(tt.message, testPrefix)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.message, err)
+			}
+			if command.Prefix != testPrefix {
+				t.Errorf("Prefix = %q, want %q", command.Prefix, testPrefix)
+			}
+			if command.Name != tt.command {
+				t.Errorf("Name = %q, want %q", command.Name, tt.command)
+			}
+			if !reflect.DeepEqual(command.Args, tt.args) {
+				t.Errorf("Args = %q, want %q", command.Args, tt.args)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		pos     int
+		reason  string
+	}{
+		{"missing prefix", "hello", 0, "parse.error.prefix"},
+		{"prefix after whitespace", "  hello", 2, "parse.error.prefix"},
+		{"empty message", "", 0, "parse.error.prefix"},
+		{"prefix only", "tamaki:", 7, "parse.error.name"},
+		{"blank name", "tamaki:  ", 7, "parse.error.name"},
+		{"empty name", "tamaki::a", 7, "parse.error.name"},
+		{"empty argument", "tamaki:a::b", 9, "parse.error.empty"},
+		{"trailing separator", "tamaki:a:", 9, "parse.error.empty"},
+		{"quote inside argument", `tamaki:a:b"c"`, 10, "parse.error.quote"},
+		{"unterminated quote", `tamaki:a:"abc`, 9, "parse.error.unclosed"},
+		{"unterminated japanese quote", "tamaki:a:「abc", 9, "parse.error.unclosed"},
+		{"escaped closing quote", `tamaki:a:"abc\"`, 9, "parse.error.unclosed"},
+		{"trailing backslash", `tamaki:a:"abc\`, 9, "parse.error.unclosed"},
+		{"text after quote", `tamaki:a:"b" c`, 13, "parse.error.separator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := Parse(tt.message, testPrefix)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %+v, want error", tt.message, command)
+			}
+			parseErr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("Parse(%q) returned %T, want *ParseError", tt.message, err)
+			}
+			if parseErr.Pos != tt.pos || parseErr.Reason != tt.reason {
+				t.Errorf("error = {%d %s}, want {%d %s}", parseErr.Pos, parseErr.Reason, tt.pos, tt.reason)
+			}
+		})
+	}
+}
+
+func TestPeekName(t *testing.T) {
+	tests := []struct {
+		message string
+		name    string
+	}{
+		{"tamaki:help", "help"},
+		{"  tamaki: kick : a", "kick"},
+		{`tamaki:text:"abc`, "text"},
+		{"tamaki:言語：「en", "言語"},
+		{"tamaki:「ありがとう」", "「ありがとう」"},
+		{"tamaki:", ""},
+		{"hello", ""},
+	}
+	for _, tt := range tests {
+		if name := PeekName(tt.message, testPrefix); name != tt.name {
+			t.Errorf("PeekName(%q) = %q, want %q", tt.message, name, tt.name)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	seeds := []string{
+		"tamaki:help",
+		"tamaki:言語：en",
+		"  tamaki: kick : a : b  ",
+		`tamaki:text:"a \"b\" c"`,
+		"tamaki:text:「a:b」",
+		`tamaki:a:"abc`,
+		`tamaki:a:"abc\`,
+		`tamaki:a:"b" c`,
+		"tamaki::",
+		"tamaki:",
+		"💙",
+		"💙「ありがとう」",
+		"",
+		"\xff\xfe",
+	}
+	for _, seed := range seeds {
+		f.Add(seed, testPrefix)
+	}
+	f.Add("💙「ありがとう」", "💙")
+	f.Add("設定：確認", "設定:")
+
+	f.Fuzz(func(t *testing.T, message string, prefix string) {
+		command, err := Parse(message, prefix)
+		if err != nil {
+			if command != nil {
+				t.Fatalf("Parse(%q, %q) returned both a command and an error", message, prefix)
+			}
+			parseErr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("Parse(%q, %q) returned %T, want *ParseError", message, prefix, err)
+			}
+			if parseErr.Pos < 0 || parseErr.Pos > len([]rune(message)) {
+				t.Fatalf("Parse(%q, %q) error position %d is out of range", message, prefix, parseErr.Pos)
+			}
+			if parseErr.Error() == "" {
+				t.Fatalf("Parse(%q, %q) returned an empty error message", message, prefix)
+			}
+			return
+		}
+		if command.Name == "" {
+			t.Fatalf("Parse(%q, %q) returned an empty command name", message, prefix)
+		}
+		if command.Prefix != prefix {
+			t.Fatalf("Parse(%q, %q) returned prefix %q", message, prefix, command.Prefix)
+		}
+	})
+}
@@ -0,0 +1,247 @@
+package cmdprocessor
+
+import (
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+
+	cmd "../cmdconst"
+	"../pendinginput"
+	"github.com/go-sql-driver/mysql"
+	"github.com/mopeneko/linethrift"
+)
+
+func (p *CommandProcessor) waitForContact(message *linethrift.Message, prompt string, handler func(mid string)) {
+	p.waitForInput(
+		message,
+		pendinginput.INPUT_CONTACT,
+		prompt,
+		func(input *pendinginput.Input) {
+			handler(input.Mid)
+		},
+	)
+}
+
+func parseDays(text string) (int, bool) {
+	if cmd.Resolve(text) == cmd.TICKETKIND_PERMANENT {
+		return 0, true
+	}
+	days, err := strconv.Atoi(text)
+	if err != nil || days < 1 {
+		return 0, false
+	}
+	return days, true
+}
+
+func (p *CommandProcessor) permissionStatus(chat string, mid string) string {
+	hasPermission, status, err := p.Utils.HasPermission(mid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return p.Utils.T(chat, "error.generic")
+	}
+	if status == "" {
+		return p.Utils.T(chat, "permission.none")
+	}
+	if status == "なし" {
+		status = p.Utils.T(chat, "common.none")
+	}
+	if hasPermission {
+		return p.Utils.T(chat, "permission.valid", status)
+	}
+	return p.Utils.T(chat, "permission.expired", status)
+}
+
+func (p *CommandProcessor) GrantPermission(message *linethrift.Message, daysText string) {
+	days, ok := parseDays(daysText)
+	if !ok {
+		p.Utils.ReplyLocalized(p.Ctx, message, "admin.days.invalid", cmd.LocalName(p.Utils.GetLanguage(message.To), cmd.TICKETKIND_PERMANENT))
+		return
+	}
+	p.waitForContact(message, "admin.grant.prompt", func(mid string) {
+		if err := p.Utils.GrantPermission(mid, days); err != nil {
+			log.Println("error:", err.Error())
+			p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+			return
+		}
+		p.Utils.Reply(p.Ctx, message, p.getDisplayName(message.To, mid)+"\n"+p.permissionStatus(message.To, mid))
+	})
+}
+
+func (p *CommandProcessor) ExtendPermission(message *linethrift.Message, daysText string) {
+	days, err := strconv.Atoi(daysText)
+	if err != nil || days < 1 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "admin.extend.invalid")
+		return
+	}
+	p.waitForContact(message, "admin.extend.prompt", func(mid string) {
+		if err := p.Utils.ExtendPermission(mid, days); err != nil {
+			log.Println("error:", err.Error())
+			p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+			return
+		}
+		p.Utils.Reply(p.Ctx, message, p.getDisplayName(message.To, mid)+"\n"+p.permissionStatus(message.To, mid))
+	})
+}
+
+func (p *CommandProcessor) RevokePermission(message *linethrift.Message) {
+	p.waitForContact(message, "admin.revoke.prompt", func(mid string) {
+		ok, err := p.Utils.RevokePermission(mid)
+		if err != nil {
+			log.Println("error:", err.Error())
+			p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+			return
+		}
+		if !ok {
+			p.Utils.ReplyLocalized(p.Ctx, message, "permission.none")
+			return
+		}
+		p.Utils.ReplyLocalized(p.Ctx, message, "admin.revoked", p.getDisplayName(message.To, mid))
+	})
+}
+
+func (p *CommandProcessor) LookupUser(message *linethrift.Message) {
+	p.waitForContact(message, "admin.lookup.prompt", func(mid string) {
+		gids, err := p.Utils.GetInvitedGroups(mid)
+		if err != nil {
+			log.Println("error:", err.Error())
+			p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+			return
+		}
+		text := p.Utils.T(message.To, "admin.user", p.getDisplayName(message.To, mid), mid)
+		text += "\n\n" + p.permissionStatus(message.To, mid)
+		if plan, err := p.Utils.GetPlan(mid); err == nil {
+			text += "\n\n" + p.planSummary(message.To, plan)
+		}
+		if isUsed, err := p.Utils.HasUsedTrial(mid); err == nil && isUsed {
+			text += "\n\n" + p.Utils.T(message.To, "admin.user.trial")
+		}
+		text += "\n\n" + p.Utils.T(message.To, "admin.user.groups", len(gids))
+		for _, gid := range gids {
+			text += "\n" + p.getGroupName(message.To, gid)
+		}
+		p.Utils.Reply(p.Ctx, message, text)
+	})
+}
+
+func (p *CommandProcessor) getGroupName(chat string, gid string) string {
+	group, err := p.Utils.Client[0].GetGroupWithoutMembers(p.Ctx, gid)
+	if err != nil {
+		return p.Utils.T(chat, "group.unknown")
+	}
+	return group.Name
+}
+
+const MAX_GROUP_LINES = 30
+
+func (p *CommandProcessor) ListAllGroups(message *linethrift.Message) {
+	rows, err := p.DB.Query(
+		`SELECT protections.id, protections.inviter, users.expair
+		FROM protections
+		LEFT JOIN users ON users.id = protections.inviter
+		WHERE protections.joined = TRUE
+		ORDER BY users.expair IS NULL, users.expair, protections.id`,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	defer rows.Close()
+	lines := []string{}
+	count := 0
+	for rows.Next() {
+		var gid string
+		var inviter sql.NullString
+		var expair mysql.NullTime
+		if err := rows.Scan(&gid, &inviter, &expair); err != nil {
+			log.Println("error:", err.Error())
+			return
+		}
+		count++
+		if count > MAX_GROUP_LINES {
+			continue
+		}
+		owner := p.Utils.T(message.To, "common.none")
+		if inviter.Valid {
+			owner = p.getDisplayName(message.To, inviter.String)
+		}
+		expiry := p.Utils.T(message.To, "common.none")
+		if expair.Valid {
+			expiry = expair.Time.Format("2006-01-02")
+		}
+		lines = append(lines, p.Utils.T(message.To, "admin.group", p.getGroupName(message.To, gid), owner, expiry))
+	}
+	if count > MAX_GROUP_LINES {
+		lines = append(lines, p.Utils.T(message.To, "broadcast.more", count-MAX_GROUP_LINES))
+	}
+	p.Utils.Reply(
+		p.Ctx, message,
+		p.Utils.T(message.To, "admin.groups", count)+"\n"+strings.Join(lines, "\n"),
+	)
+}
+
+func (p *CommandProcessor) CheckFleet(message *linethrift.Message) {
+	lines := []string{}
+	for i, cl := range p.Utils.Client {
+		name := p.getDisplayName(message.To, p.Utils.Mids[i])
+		joined, err := cl.GetGroupIdsJoined(p.Ctx)
+		if err != nil {
+			log.Println("error:", err.Error())
+			lines = append(lines, p.Utils.T(message.To, "admin.fleet.down", i+1, name))
+			continue
+		}
+		invited, _ := cl.GetGroupIdsInvited(p.Ctx)
+		lines = append(lines, p.Utils.T(message.To, "admin.fleet.up", i+1, name, len(joined), len(invited)))
+	}
+	p.Utils.Reply(
+		p.Ctx, message,
+		p.Utils.T(message.To, "admin.fleet")+"\n"+strings.Join(lines, "\n"),
+	)
+}
+
+func (p *CommandProcessor) AddAdmin(message *linethrift.Message) {
+	p.waitForContact(message, "admin.add.prompt", func(mid string) {
+		ok, err := p.Utils.AddAdmin(mid)
+		if err != nil {
+			log.Println("error:", err.Error())
+			p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+			return
+		}
+		if !ok {
+			p.Utils.ReplyLocalized(p.Ctx, message, "admin.already", p.getDisplayName(message.To, mid))
+			return
+		}
+		p.Utils.ReplyLocalized(p.Ctx, message, "admin.added", p.getDisplayName(message.To, mid))
+	})
+}
+
+func (p *CommandProcessor) RemoveAdmin(message *linethrift.Message) {
+	p.waitForContact(message, "admin.remove.prompt", func(mid string) {
+		ok, err := p.Utils.RemoveAdmin(mid)
+		if err != nil {
+			log.Println("error:", err.Error())
+			p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+			return
+		}
+		if !ok {
+			p.Utils.ReplyLocalized(p.Ctx, message, "admin.notfound")
+			return
+		}
+		p.Utils.ReplyLocalized(p.Ctx, message, "admin.removed", p.getDisplayName(message.To, mid))
+	})
+}
+
+func (p *CommandProcessor) ListAdmins(message *linethrift.Message) {
+	mids, err := p.Utils.GetAdmins()
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	text := p.Utils.T(message.To, "admin.list")
+	for _, mid := range mids {
+		text += "\n" + p.getDisplayName(message.To, mid)
+	}
+	p.Utils.Reply(p.Ctx, message, text)
+}
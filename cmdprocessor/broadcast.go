@@ -0,0 +1,173 @@
+package cmdprocessor
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cmd "../cmdconst"
+	"../pendinginput"
+	"github.com/mopeneko/linethrift"
+)
+
+const (
+	BROADCAST_INTERVAL     = time.Second * 2
+	MAX_BROADCAST_FAILURES = 10
+)
+
+var broadcastConfirmTexts = []string{"はい", "yes"}
+
+type broadcastFilter struct {
+	expiryDays  int
+	protections map[string]bool
+}
+
+func parseBroadcastFilter(text string) (*broadcastFilter, string, error) {
+	filter := &broadcastFilter{-1, map[string]bool{}}
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '　' || r == ',' || r == '、'
+	})
+	for _, field := range fields {
+		if cmd.Resolve(field) == cmd.BROADCAST_ALL {
+			continue
+		}
+		pair := strings.SplitN(strings.Replace(field, "＝", "=", 1), "=", 2)
+		if len(pair) != 2 {
+			return nil, field, errors.New("Filter is wrong.")
+		}
+		if cmd.Resolve(pair[0]) == cmd.BROADCAST_EXPIRY {
+			days, err := strconv.Atoi(pair[1])
+			if err != nil || days < 0 {
+				return nil, field, errors.New("Filter is wrong.")
+			}
+			filter.expiryDays = days
+			continue
+		}
+		protectionType, ok := bulkSettingKeys[strings.ToLower(pair[0])]
+		if !ok {
+			protectionType, ok = settingProtectionTypes[cmd.Resolve(pair[0])]
+		}
+		isEnabled, valid := parseSwitch(pair[1])
+		if !ok || !valid {
+			return nil, field, errors.New("Filter is wrong.")
+		}
+		filter.protections[protectionType] = isEnabled
+	}
+	return filter, "", nil
+}
+
+func (p *CommandProcessor) getBroadcastTargets(filter *broadcastFilter) ([]string, error) {
+	query := `SELECT protections.id
+		FROM protections
+		JOIN users ON users.id = protections.inviter
+		WHERE protections.joined = TRUE AND (users.expair IS NULL OR users.expair >= CURDATE())`
+	args := []interface{}{}
+	if filter.expiryDays >= 0 {
+		query += ` AND users.expair <= DATE_ADD(CURDATE(), INTERVAL ? DAY)`
+		args = append(args, filter.expiryDays)
+	}
+	for _, protectionType := range protectionTypes {
+		if isEnabled, ok := filter.protections[protectionType]; ok {
+			query += ` AND protections.` + protectionType + `protection = ?`
+			args = append(args, isEnabled)
+		}
+	}
+	rows, err := p.DB.Query(query+` ORDER BY protections.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	gids := []string{}
+	for rows.Next() {
+		var gid string
+		if err := rows.Scan(&gid); err != nil {
+			return nil, err
+		}
+		gids = append(gids, gid)
+	}
+	return gids, rows.Err()
+}
+
+func (p *CommandProcessor) broadcast(gids []string, text string) (int, []string) {
+	queues := make([][]string, len(p.Utils.Client))
+	for i, gid := range gids {
+		queues[i%len(queues)] = append(queues[i%len(queues)], gid)
+	}
+
+	mu := &sync.Mutex{}
+	sent := 0
+	failed := []string{}
+	wg := &sync.WaitGroup{}
+	for i, queue := range queues {
+		wg.Add(1)
+		go func(x *linethrift.TalkServiceClient, queue []string) {
+			defer wg.Done()
+			for j, gid := range queue {
+				if j > 0 {
+					time.Sleep(BROADCAST_INTERVAL)
+				}
+				_, err := x.SendMessage(p.Ctx, 0, p.Utils.GenerateTextMessage(gid, text))
+				mu.Lock()
+				if err != nil {
+					log.Println("error:", err.Error())
+					failed = append(failed, gid)
+				} else {
+					sent++
+				}
+				mu.Unlock()
+			}
+		}(p.Utils.Client[i], queue)
+	}
+	wg.Wait()
+	return sent, failed
+}
+
+func (p *CommandProcessor) Broadcast(message *linethrift.Message, filterText string, text string) {
+	filter, field, err := parseBroadcastFilter(filterText)
+	if err != nil {
+		p.Utils.ReplyLocalized(p.Ctx, message, "broadcast.filter.invalid", field)
+		return
+	}
+	gids, err := p.getBroadcastTargets(filter)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if len(gids) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "broadcast.empty")
+		return
+	}
+
+	p.Utils.ReplyLocalized(p.Ctx, message, "broadcast.preview", len(gids), text)
+	p.waitForInput(message, pendinginput.INPUT_TEXT, "broadcast.confirm", func(input *pendinginput.Input) {
+		confirmed := false
+		for _, confirmText := range broadcastConfirmTexts {
+			if strings.EqualFold(input.Text, confirmText) {
+				confirmed = true
+			}
+		}
+		if !confirmed {
+			p.Utils.ReplyLocalized(p.Ctx, message, "input.cancelled")
+			return
+		}
+
+		p.Utils.ReplyLocalized(p.Ctx, message, "broadcast.started", len(gids), len(p.Utils.Client))
+		go func() {
+			start := time.Now()
+			sent, failed := p.broadcast(gids, text)
+			report := p.Utils.T(message.To, "broadcast.report", sent, len(failed), time.Since(start).Round(time.Second).String())
+			for i, gid := range failed {
+				if i == MAX_BROADCAST_FAILURES {
+					report += "\n" + p.Utils.T(message.To, "broadcast.more", len(failed)-i)
+					break
+				}
+				report += "\n" + p.getGroupName(message.To, gid)
+			}
+			p.Utils.Reply(p.Ctx, message, report)
+		}()
+	})
+}
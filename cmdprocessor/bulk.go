@@ -0,0 +1,156 @@
+package cmdprocessor
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	cmd "../cmdconst"
+	"github.com/mopeneko/linethrift"
+)
+
+var bulkSettingKeys = map[string]string{
+	"name":   "name",
+	"icon":   "image",
+	"image":  "image",
+	"url":    "url",
+	"invite": "invite",
+}
+
+func (p *CommandProcessor) getProtections(gid string) (map[string]bool, error) {
+	values := make([][]byte, len(protectionTypes))
+	err := p.DB.QueryRow(
+		`SELECT nameprotection, imageprotection, urlprotection, inviteprotection
+		FROM protections
+		WHERE id = ?`,
+		gid,
+	).Scan(&values[0], &values[1], &values[2], &values[3])
+	if err != nil {
+		return nil, err
+	}
+	protections := map[string]bool{}
+	for i, protectionType := range protectionTypes {
+		protections[protectionType] = len(values[i]) > 0 && values[i][0] == 1
+	}
+	return protections, nil
+}
+
+func (p *CommandProcessor) setProtections(gid string, settings map[string]bool) (map[string]bool, error) {
+	current, err := p.getProtections(gid)
+	if err != nil {
+		return nil, err
+	}
+	isAlready := map[string]bool{}
+	columns := []string{}
+	args := []interface{}{}
+	for _, protectionType := range protectionTypes {
+		isEnabled, ok := settings[protectionType]
+		if !ok {
+			continue
+		}
+		if current[protectionType] == isEnabled {
+			isAlready[protectionType] = true
+			continue
+		}
+		columns = append(columns, protectionType+"protection = ?")
+		args = append(args, isEnabled)
+		if !isEnabled {
+			continue
+		}
+		switch protectionType {
+		case "name":
+			group, err := p.Utils.GetRandomClient().GetGroup(p.Ctx, gid)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, "name = ?")
+			args = append(args, group.Name)
+		case "image":
+			if err := p.Utils.DownloadGroupPicture(gid, "cache/"+gid+".jpg"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return isAlready, nil
+	}
+
+	_, err = p.DB.Exec(
+		`UPDATE protections SET `+strings.Join(columns, ", ")+` WHERE id = ?`,
+		append(args, gid)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings["url"] && !isAlready["url"] {
+		cl := p.Utils.GetRandomClient()
+		group, err := cl.GetGroup(p.Ctx, gid)
+		if err != nil {
+			log.Println("error:", err.Error())
+		} else if !group.PreventedJoinByTicket {
+			group.PreventedJoinByTicket = true
+			cl.UpdateGroup(p.Ctx, 0, group)
+		}
+	}
+	return isAlready, nil
+}
+
+func parseSwitch(text string) (bool, bool) {
+	switch strings.ToLower(text) {
+	case "オン", "on":
+		return true, true
+	case "オフ", "off":
+		return false, true
+	}
+	return false, false
+}
+
+func parseBulkSettings(text string) (map[string]bool, string, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '　' || r == ',' || r == '、'
+	})
+	if len(fields) == 0 {
+		return nil, "", errors.New("Bulk setting is empty.")
+	}
+	settings := map[string]bool{}
+	if len(fields) == 1 && !strings.ContainsAny(fields[0], "=＝") {
+		isEnabled, ok := parseSwitch(fields[0])
+		if !ok {
+			return nil, fields[0], errors.New("Switch string is wrong.")
+		}
+		for _, protectionType := range protectionTypes {
+			settings[protectionType] = isEnabled
+		}
+		return settings, "", nil
+	}
+	for _, field := range fields {
+		pair := strings.SplitN(strings.Replace(field, "＝", "=", 1), "=", 2)
+		if len(pair) != 2 {
+			return nil, field, errors.New("Assignment is wrong.")
+		}
+		protectionType, ok := bulkSettingKeys[strings.ToLower(pair[0])]
+		if !ok {
+			protectionType, ok = settingProtectionTypes[cmd.Resolve(pair[0])]
+		}
+		isEnabled, valid := parseSwitch(pair[1])
+		if !ok || !valid {
+			return nil, field, errors.New("Assignment is wrong.")
+		}
+		settings[protectionType] = isEnabled
+	}
+	return settings, "", nil
+}
+
+func (p *CommandProcessor) BulkSetting(message *linethrift.Message, text string) {
+	settings, field, err := parseBulkSettings(text)
+	if err != nil {
+		if field == "" {
+			p.Utils.ReplyLocalized(p.Ctx, message, "bulk.empty")
+		} else {
+			p.Utils.ReplyLocalized(p.Ctx, message, "bulk.invalid", field)
+		}
+		return
+	}
+	p.Utils.Reply(p.Ctx, message, p.applySettings(message.To, settings))
+}
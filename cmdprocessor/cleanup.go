@@ -0,0 +1,83 @@
+package cmdprocessor
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"../pendinginput"
+	"github.com/mopeneko/linethrift"
+)
+
+const MAX_CLEANUP_LINES = 30
+
+func (p *CommandProcessor) cleanupLines(chat string, lines []string) string {
+	text := ""
+	for i, line := range lines {
+		if i == MAX_CLEANUP_LINES {
+			text += "\n" + p.Utils.T(chat, "broadcast.more", len(lines)-i)
+			break
+		}
+		text += "\n" + line
+	}
+	return text
+}
+
+func (p *CommandProcessor) PreviewCleanup(message *linethrift.Message) {
+	plan, err := p.Utils.PlanCleanup()
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if len(plan.Groups) == 0 && len(plan.Invitations) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "cleanup.empty")
+		return
+	}
+
+	groups := []string{}
+	for _, group := range plan.Groups {
+		groups = append(groups, p.Utils.T(
+			message.To, "admin.group",
+			p.getGroupName(message.To, group.Gid), p.getDisplayName(message.To, group.Inviter), group.Expair.Format("2006-01-02"),
+		))
+	}
+	owners := []string{}
+	for _, mid := range plan.Owners() {
+		owners = append(owners, p.getDisplayName(message.To, mid))
+	}
+	invitations := []string{}
+	for _, invitation := range plan.Invitations {
+		invitations = append(invitations, p.Utils.T(
+			message.To, "cleanup.invitation",
+			invitation.Client+1, p.getGroupName(message.To, invitation.Gid),
+		))
+	}
+	text := p.Utils.T(message.To, "cleanup.groups", len(groups)) + p.cleanupLines(message.To, groups)
+	text += "\n\n" + p.Utils.T(message.To, "cleanup.owners", len(owners)) + p.cleanupLines(message.To, owners)
+	text += "\n\n" + p.Utils.T(message.To, "cleanup.invitations", len(invitations)) + p.cleanupLines(message.To, invitations)
+	p.Utils.Reply(p.Ctx, message, text)
+
+	p.waitForInput(message, pendinginput.INPUT_TEXT, "cleanup.confirm", func(input *pendinginput.Input) {
+		confirmed := false
+		for _, confirmText := range broadcastConfirmTexts {
+			if strings.EqualFold(input.Text, confirmText) {
+				confirmed = true
+			}
+		}
+		if !confirmed {
+			p.Utils.ReplyLocalized(p.Ctx, message, "input.cancelled")
+			return
+		}
+
+		p.Utils.ReplyLocalized(p.Ctx, message, "cleanup.started")
+		go func() {
+			start := time.Now()
+			left := p.Utils.ExecuteCleanup(plan)
+			p.Utils.ReplyLocalized(
+				p.Ctx, message, "cleanup.finished",
+				left, len(plan.Invitations), time.Since(start).Round(time.Second).String(),
+			)
+		}()
+	})
+}
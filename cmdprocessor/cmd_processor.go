@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
-	cmd "../cmdconst"
+	"../i18n"
+	"../pendinginput"
 	"../utils"
 	sigar "github.com/cloudfoundry/gosigar"
 	"github.com/mopeneko/linethrift"
@@ -19,18 +21,14 @@ type CommandProcessor struct {
 	Utils            *utils.Utils
 	DB               *sql.DB
 	Ctx              context.Context
-	AllSetting       []string
 	StartProgramTime time.Time
+	Inputs           *pendinginput.Manager
+	lockdowns        *lockdownList
 }
 
 func Init(u *utils.Utils, db *sql.DB, ctx context.Context, startProgramTime time.Time) *CommandProcessor {
-	allSetting := []string{
-		cmd.SETTING_NAME,
-		cmd.SETTING_ICON,
-		cmd.SETTING_URL,
-		cmd.SETTING_INVITE,
-	}
-	return &CommandProcessor{u, db, ctx, allSetting, startProgramTime}
+	lockdowns := &lockdownList{list: map[string]*Lockdown{}}
+	return &CommandProcessor{u, db, ctx, startProgramTime, pendinginput.New(), lockdowns}
 }
 
 func (p *CommandProcessor) isEnabledString(text string) (bool, error) {
@@ -45,12 +43,12 @@ func (p *CommandProcessor) isEnabledString(text string) (bool, error) {
 }
 
 func (p *CommandProcessor) CheckSendSpeed(message *linethrift.Message) {
-	cl := p.Utils.GetRandomClient()
-	msg := p.Utils.GenerateTextMessage(message.To, "計測中")
+	cl := p.Utils.ReplyClient(message)
+	msg := p.Utils.GenerateTextMessage(p.Utils.ReplyChat(message), p.Utils.T(message.To, "speed.measuring"))
 	start := time.Now()
 	cl.SendMessage(p.Ctx, 0, msg)
 	end := time.Now()
-	msg.Text = fmt.Sprintf("%f秒", end.Sub(start).Seconds())
+	msg.Text = p.Utils.T(message.To, "speed.result", end.Sub(start).Seconds())
 	cl.SendMessage(p.Ctx, 0, msg)
 }
 
@@ -66,174 +64,109 @@ func (p *CommandProcessor) isAlreadyEnabledProtection(gid string, protectionType
 	return isAlready, nil
 }
 
-func (p *CommandProcessor) buildSettingResultText(setType string, isAlready bool, isEnabled bool) string {
-	recvmesg := setType
-	if !isAlready {
-		recvmesg += "を"
-		if isEnabled {
-			recvmesg += "オン"
-		} else {
-			recvmesg += "オフ"
-		}
-		recvmesg += "にしたのですっ"
-	} else {
-		recvmesg += "は既に"
-		if isEnabled {
-			recvmesg += "オン"
-		} else {
-			recvmesg += "オフ"
-		}
-		recvmesg += "なのですっ"
+func (p *CommandProcessor) protectionName(gid string, protectionType string) string {
+	return p.Utils.T(gid, "protection."+protectionType)
+}
+
+func (p *CommandProcessor) switchName(gid string, isEnabled bool) string {
+	if isEnabled {
+		return p.Utils.T(gid, "switch.on")
 	}
+	return p.Utils.T(gid, "switch.off")
+}
 
-	return recvmesg
+func (p *CommandProcessor) buildSettingResultText(gid string, protectionType string, isAlready bool, isEnabled bool) string {
+	if isAlready {
+		return p.Utils.T(gid, "setting.already", p.protectionName(gid, protectionType), p.switchName(gid, isEnabled))
+	}
+	return p.Utils.T(gid, "setting.changed", p.protectionName(gid, protectionType), p.switchName(gid, isEnabled))
 }
 
-func (p *CommandProcessor) SwitchURLProtection(message *linethrift.Message, isEnabledText string) {
-	isEnabled, _ := p.isEnabledString(isEnabledText)
-	cl := p.Utils.GetRandomClient()
-	isAlready, err := p.isAlreadyEnabledProtection(message.To, "url", isEnabled)
+func (p *CommandProcessor) setProtection(gid string, protectionType string, isEnabled bool) (bool, error) {
+	isAlready, err := p.isAlreadyEnabledProtection(gid, protectionType, isEnabled)
 	if err != nil {
-		log.Println("error:", err)
+		return false, err
 	}
-	if !isAlready {
-		if isEnabled {
-			group, err := cl.GetGroup(p.Ctx, message.To)
+	if isAlready {
+		return true, nil
+	}
+	if isEnabled {
+		switch protectionType {
+		case "url":
+			cl := p.Utils.GetRandomClient()
+			group, err := cl.GetGroup(p.Ctx, gid)
 			if err != nil {
-				log.Println("error:", err.Error())
-				return
+				return false, err
 			}
 			if !group.PreventedJoinByTicket {
 				group.PreventedJoinByTicket = true
 				cl.UpdateGroup(p.Ctx, 0, group)
 			}
-		}
-		_, err = p.DB.Exec(
-			`UPDATE protections SET urlprotection = ? WHERE id = ?`,
-			isEnabled,
-			message.To,
-		)
-		if err != nil {
-			log.Println("error:", err.Error())
-			return
-		}
-	}
-	cl.SendMessage(
-		p.Ctx, 0,
-		p.Utils.GenerateTextMessage(
-			message.To,
-			p.buildSettingResultText("招待リンク拒否", isAlready, isEnabled),
-		),
-	)
-}
-
-func (p *CommandProcessor) SwitchNameProtection(message *linethrift.Message, isEnabledText string) {
-	isEnabled, _ := p.isEnabledString(isEnabledText)
-	cl := p.Utils.GetRandomClient()
-	isAlready, err := p.isAlreadyEnabledProtection(message.To, "name", isEnabled)
-	if err != nil {
-		log.Println("error:", err)
-	}
-	if !isAlready {
-		if isEnabled {
-			group, err := cl.GetGroup(p.Ctx, message.To)
+		case "name":
+			group, err := p.Utils.GetRandomClient().GetGroup(p.Ctx, gid)
 			if err != nil {
-				log.Println("error:", err.Error())
-				return
+				return false, err
 			}
 			_, err = p.DB.Exec(
 				`UPDATE protections SET name = ? WHERE id = ?`,
 				group.Name,
-				message.To,
+				gid,
 			)
 			if err != nil {
-				log.Println("error:", err.Error())
-				return
+				return false, err
+			}
+		case "image":
+			err = p.Utils.DownloadGroupPicture(gid, "cache/"+gid+".jpg")
+			if err != nil {
+				return false, err
 			}
-		}
-		_, err = p.DB.Exec(
-			`UPDATE protections SET nameprotection = ? WHERE id = ?`,
-			isEnabled,
-			message.To,
-		)
-		if err != nil {
-			log.Println("error:", err.Error())
-			return
 		}
 	}
-	cl.SendMessage(
-		p.Ctx, 0,
-		p.Utils.GenerateTextMessage(
-			message.To,
-			p.buildSettingResultText("グループ名ロック", isAlready, isEnabled),
-		),
+	_, err = p.DB.Exec(
+		`UPDATE protections SET `+protectionType+`protection = ? WHERE id = ?`,
+		isEnabled,
+		gid,
 	)
+	if err != nil {
+		return false, err
+	}
+	return false, nil
 }
 
-func (p *CommandProcessor) SwitchIconProtection(message *linethrift.Message, isEnabledText string) {
+func (p *CommandProcessor) switchProtection(message *linethrift.Message, protectionType string, isEnabledText string) {
 	isEnabled, _ := p.isEnabledString(isEnabledText)
-	cl := p.Utils.GetRandomClient()
-	isAlready, err := p.isAlreadyEnabledProtection(message.To, "image", isEnabled)
-	if err != nil {
-		log.Println("error:", err)
+	if isEnabled && !p.AllowFeature(message, utils.Feature(protectionType)) {
+		return
 	}
-	if !isAlready {
-		if isEnabled {
-			err = p.Utils.DownloadGroupPicture(message.To, "cache/"+message.To+".jpg")
-			if err != nil {
-				log.Println("error:", err.Error())
-				return
-			}
-		}
-		_, err = p.DB.Exec(
-			`UPDATE protections SET imageprotection = ? WHERE id = ?`,
-			isEnabled,
-			message.To,
-		)
-		if err != nil {
-			log.Println("error:", err.Error())
-			return
-		}
+	isAlready, err := p.setProtection(message.To, protectionType, isEnabled)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return
 	}
-	cl.SendMessage(
-		p.Ctx, 0,
-		p.Utils.GenerateTextMessage(
-			message.To,
-			p.buildSettingResultText("アイコンロック", isAlready, isEnabled),
-		),
+	p.rememberManualSetting(message.To, protectionType, isEnabled)
+	p.Utils.Reply(
+		p.Ctx, message,
+		p.buildSettingResultText(message.To, protectionType, isAlready, isEnabled),
 	)
 }
 
+func (p *CommandProcessor) SwitchURLProtection(message *linethrift.Message, isEnabledText string) {
+	p.switchProtection(message, "url", isEnabledText)
+}
+
+func (p *CommandProcessor) SwitchNameProtection(message *linethrift.Message, isEnabledText string) {
+	p.switchProtection(message, "name", isEnabledText)
+}
+
+func (p *CommandProcessor) SwitchIconProtection(message *linethrift.Message, isEnabledText string) {
+	p.switchProtection(message, "image", isEnabledText)
+}
+
 func (p *CommandProcessor) SwitchInviteProtection(message *linethrift.Message, isEnabledText string) {
-	isEnabled, _ := p.isEnabledString(isEnabledText)
-	cl := p.Utils.GetRandomClient()
-	isAlready, err := p.isAlreadyEnabledProtection(message.To, "invite", isEnabled)
-	if err != nil {
-		log.Println("error:", err)
-	}
-	if !isAlready {
-		_, err = p.DB.Exec(
-			`UPDATE protections SET inviteprotection = ? WHERE id = ?`,
-			isEnabled,
-			message.To,
-		)
-		if err != nil {
-			log.Println("error:", err.Error())
-			return
-		}
-	}
-	cl.SendMessage(
-		p.Ctx, 0,
-		p.Utils.GenerateTextMessage(
-			message.To,
-			p.buildSettingResultText("招待拒否", isAlready, isEnabled),
-		),
-	)
+	p.switchProtection(message, "invite", isEnabledText)
 }
 
 func (p *CommandProcessor) CheckSetting(message *linethrift.Message) {
-	client := p.Utils.GetRandomClient()
-
 	protection := [4]([]byte){}
 	var inviterFetched string
 	var subAdminFetched sql.NullString
@@ -254,27 +187,10 @@ func (p *CommandProcessor) CheckSetting(message *linethrift.Message) {
 
 	if err != nil {
 		log.Println("error:", err.Error())
-		client.SendMessage(
-			p.Ctx,
-			0,
-			p.Utils.GenerateTextMessage(
-				message.To,
-				"エラーが発生したのですっ",
-			),
-		)
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
 		return
 	}
 
-	protectionText := [4]string{}
-
-	for i, v := range protection {
-		if v[0] == 1 {
-			protectionText[i] = "オン"
-		} else {
-			protectionText[i] = "オフ"
-		}
-	}
-
 	inviter := ""
 	subAdmin := ""
 
@@ -282,7 +198,7 @@ func (p *CommandProcessor) CheckSetting(message *linethrift.Message) {
 	if err == nil {
 		inviter = contact.DisplayName
 	} else {
-		inviter = "アカウント削除"
+		inviter = p.Utils.T(message.To, "account.deleted")
 	}
 
 	if subAdminFetched.Valid {
@@ -290,26 +206,21 @@ func (p *CommandProcessor) CheckSetting(message *linethrift.Message) {
 		if err == nil {
 			subAdmin = contact.DisplayName
 		} else {
-			subAdmin = "アカウント削除"
+			subAdmin = p.Utils.T(message.To, "account.deleted")
 		}
 	} else {
-		subAdmin = "なし"
+		subAdmin = p.Utils.T(message.To, "common.none")
 	}
 
 	status := ""
 
-	for i, switchText := range protectionText {
-		status += p.AllSetting[i] + " -> " + switchText + "\n"
+	for i, protectionType := range protectionTypes {
+		status += p.protectionName(message.To, protectionType) + " -> " + p.switchName(message.To, protection[i][0] == 1) + "\n"
 	}
-	status += "\n招待者 -> " + inviter + "\nサブ管理者 -> " + subAdmin
+	status += p.Utils.T(message.To, "setting.members", inviter, subAdmin)
+	status += p.buildScheduleText(message.To)
 
-	client.SendMessage(
-		p.Ctx, 0,
-		p.Utils.GenerateTextMessage(
-			message.To,
-			status,
-		),
-	)
+	p.Utils.Reply(p.Ctx, message, status)
 }
 
 func (p *CommandProcessor) CheckPermission(message *linethrift.Message) {
@@ -320,16 +231,26 @@ func (p *CommandProcessor) CheckPermission(message *linethrift.Message) {
 	}
 	recvmesg := ""
 	if status != "" {
+		if status == "なし" {
+			status = p.Utils.T(message.To, "common.none")
+		}
 		if hasPermission {
-			recvmesg = "あなたは権限を所持してるのですっ"
+			recvmesg = p.Utils.T(message.To, "permission.valid", status)
 		} else {
-			recvmesg = "あなたの権限は既に失効されているみたいです。。。"
+			recvmesg = p.Utils.T(message.To, "permission.expired", status)
 		}
-		recvmesg += fmt.Sprintf("\n\n[有効期限]\n%s", status)
 	} else {
-		recvmesg = "あなたは権限を所持していないみたいです。。。"
+		recvmesg = p.Utils.T(message.To, "permission.none")
+	}
+	if hasPermission {
+		plan, err := p.Utils.GetPlan(message.From)
+		if err != nil {
+			log.Println("error:", err.Error())
+		} else {
+			recvmesg += "\n\n" + p.planSummary(message.To, plan)
+		}
 	}
-	p.Utils.SendMessageWithRandomClient(p.Ctx, message.To, recvmesg)
+	p.Utils.Reply(p.Ctx, message, recvmesg)
 }
 
 func (p *CommandProcessor) formatMB(v uint64) uint64 {
@@ -346,9 +267,9 @@ func (p *CommandProcessor) SendStatus(message *linethrift.Message) {
 	mem := sigar.Mem{}
 	mem.Get()
 
-	p.Utils.SendMessageWithRandomClient(
+	p.Utils.Reply(
 		p.Ctx,
-		message.To,
+		message,
 		fmt.Sprintf(
 			`[Uptime]
 %s
@@ -377,20 +298,10 @@ func (p *CommandProcessor) CheckKickers(message *linethrift.Message) {
 		}
 	}
 	if len(validMids) == len(p.Utils.Client) {
-		p.Utils.SendMessageWithRandomClient(
-			p.Ctx,
-			message.To,
-			"全員いるのですっ",
-		)
+		p.Utils.ReplyLocalized(p.Ctx, message, "kickers.full")
 	} else {
 		notValidSize := len(p.Utils.Client) - len(validMids)
-		p.Utils.Client[0].SendMessage(
-			p.Ctx, 0,
-			p.Utils.GenerateTextMessage(
-				message.To,
-				fmt.Sprintf("%d体補充するのですっ", notValidSize),
-			),
-		)
+		p.Utils.ReplyLocalized(p.Ctx, message, "kickers.missing", notValidSize)
 		notValidClients := []*linethrift.TalkServiceClient{}
 		for i, mid := range p.Utils.Mids {
 			isValid := false
@@ -437,12 +348,183 @@ func (p *CommandProcessor) LeaveBots(message *linethrift.Message) {
 		}(client)
 	}
 	wg.Wait()
+	p.Utils.MarkGroupLeft(message.To)
 }
 
-func (p *CommandProcessor) ChangeSubAdmin(message *linethrift.Message, list map[string]bool) {
-	list[message.To] = true
-	p.Utils.SendMessageWithRandomClient(
-		p.Ctx, message.To,
-		"サブ管理者にしたいアカウントの連絡先を送信するのですっ",
+func (p *CommandProcessor) waitForInput(message *linethrift.Message, inputType pendinginput.InputType, prompt string, handler func(input *pendinginput.Input)) {
+	gid := message.To
+	p.Inputs.Wait(&pendinginput.Request{
+		Chat:    gid,
+		Mid:     message.From,
+		Type:    inputType,
+		Handler: handler,
+		OnTimeout: func() {
+			p.Utils.ReplyLocalized(p.Ctx, message, "input.timeout")
+		},
+		OnCancel: func() {
+			p.Utils.ReplyLocalized(p.Ctx, message, "input.cancelled")
+		},
+	})
+	p.Utils.Reply(
+		p.Ctx, message,
+		p.Utils.T(gid, prompt)+"\n"+p.Utils.T(gid, "input.cancel"),
 	)
 }
+
+func inputMids(input *pendinginput.Input) []string {
+	if input.Type == pendinginput.INPUT_MENTION {
+		return input.Mids
+	}
+	return []string{input.Mid}
+}
+
+func (p *CommandProcessor) SetSubAdmin(gid string, mid string) error {
+	_, err := p.DB.Exec(
+		`UPDATE protections SET subadmin = ? WHERE id = ?`,
+		mid, gid,
+	)
+	return err
+}
+
+func (p *CommandProcessor) ChangeSubAdmin(message *linethrift.Message) {
+	gid := message.To
+	p.waitForInput(
+		message,
+		pendinginput.INPUT_CONTACT|pendinginput.INPUT_MENTION,
+		"subadmin.prompt",
+		func(input *pendinginput.Input) {
+			mids := inputMids(input)
+			if len(mids) != 1 {
+				p.Utils.ReplyLocalized(p.Ctx, message, "subadmin.onlyone")
+				return
+			}
+			contact, err := p.Utils.Client[0].GetContact(p.Ctx, mids[0])
+			if err != nil {
+				p.Utils.ReplyLocalized(p.Ctx, message, "error.contact")
+				return
+			}
+			if err := p.SetSubAdmin(gid, mids[0]); err != nil {
+				p.Utils.ReplyLocalized(p.Ctx, message, "error.contact")
+				log.Println("error:", err.Error())
+				return
+			}
+			p.Utils.ReplyLocalized(p.Ctx, message, "subadmin.changed", contact.DisplayName)
+		},
+	)
+}
+
+func (p *CommandProcessor) AddTrustedInviter(message *linethrift.Message) {
+	p.waitForInput(
+		message,
+		pendinginput.INPUT_CONTACT|pendinginput.INPUT_MENTION,
+		"trusted.add.prompt",
+		func(input *pendinginput.Input) {
+			for _, mid := range inputMids(input) {
+				p.registerTrustedInviter(message, mid)
+			}
+		},
+	)
+}
+
+func (p *CommandProcessor) RemoveTrustedInviter(message *linethrift.Message) {
+	p.waitForInput(
+		message,
+		pendinginput.INPUT_CONTACT|pendinginput.INPUT_MENTION,
+		"trusted.remove.prompt",
+		func(input *pendinginput.Input) {
+			for _, mid := range inputMids(input) {
+				p.unregisterTrustedInviter(message, mid)
+			}
+		},
+	)
+}
+
+func (p *CommandProcessor) registerTrustedInviter(message *linethrift.Message, mid string) {
+	gid := message.To
+	contact, err := p.Utils.Client[0].GetContact(p.Ctx, mid)
+	if err != nil {
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.contact")
+		return
+	}
+	result, err := p.DB.Exec(
+		`INSERT IGNORE INTO trustedinviters(gid, mid) VALUES (?, ?)`,
+		gid, mid,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "trusted.already", contact.DisplayName)
+		return
+	}
+	p.Utils.ReplyLocalized(p.Ctx, message, "trusted.added", contact.DisplayName)
+}
+
+func (p *CommandProcessor) unregisterTrustedInviter(message *linethrift.Message, mid string) {
+	gid := message.To
+	result, err := p.DB.Exec(
+		`DELETE FROM trustedinviters WHERE gid = ? AND mid = ?`,
+		gid, mid,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "trusted.notfound")
+		return
+	}
+	p.Utils.ReplyLocalized(p.Ctx, message, "trusted.removed")
+}
+
+func (p *CommandProcessor) CheckTrustedInviters(message *linethrift.Message) {
+	rows, err := p.DB.Query(
+		`SELECT mid FROM trustedinviters WHERE gid = ?`,
+		message.To,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		var mid string
+		if err := rows.Scan(&mid); err != nil {
+			log.Println("error:", err.Error())
+			return
+		}
+		contact, err := p.Utils.Client[0].GetContact(p.Ctx, mid)
+		if err == nil {
+			names = append(names, contact.DisplayName)
+		} else {
+			names = append(names, p.Utils.T(message.To, "account.deleted"))
+		}
+	}
+	if len(names) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "trusted.empty")
+		return
+	}
+	p.Utils.Reply(
+		p.Ctx, message,
+		p.Utils.T(message.To, "trusted.list")+"\n"+strings.Join(names, "\n"),
+	)
+}
+
+func (p *CommandProcessor) ChangeLanguage(message *linethrift.Message, name string) {
+	language, ok := i18n.Resolve(name)
+	if !ok {
+		p.Utils.ReplyLocalized(p.Ctx, message, "language.unknown")
+		return
+	}
+	if err := p.Utils.SetLanguage(message.To, language); err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	p.Utils.ReplyLocalized(p.Ctx, message, "language.changed")
+}
@@ -0,0 +1,261 @@
+package cmdprocessor
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mopeneko/linethrift"
+)
+
+const (
+	LOCKDOWN_DEFAULT_MINUTES = 30
+	LOCKDOWN_MAX_MINUTES     = 24 * 60
+)
+
+var protectionTypes = []string{"name", "image", "url", "invite"}
+
+type Lockdown struct {
+	Until     time.Time
+	Previous  map[string]bool
+	Cancelled int
+	Kicked    int
+	timer     *time.Timer
+}
+
+type lockdownList struct {
+	sync.Mutex
+	list map[string]*Lockdown
+}
+
+func (p *CommandProcessor) StartLockdown(message *linethrift.Message, minutesText string) {
+	minutes := LOCKDOWN_DEFAULT_MINUTES
+	if minutesText != "" {
+		var err error
+		minutes, err = strconv.Atoi(minutesText)
+		if err != nil || minutes <= 0 || minutes > LOCKDOWN_MAX_MINUTES {
+			p.Utils.ReplyLocalized(p.Ctx, message, "lockdown.range", LOCKDOWN_MAX_MINUTES)
+			return
+		}
+	}
+	gid := message.To
+
+	p.lockdowns.Lock()
+	if lockdown, ok := p.lockdowns.list[gid]; ok {
+		p.lockdowns.Unlock()
+		p.Utils.ReplyLocalized(p.Ctx, message, "lockdown.already", lockdown.Until.Format("15:04"))
+		return
+	}
+	until := time.Now().Add(time.Minute * time.Duration(minutes))
+	lockdown := &Lockdown{Until: until, Previous: map[string]bool{}}
+	p.lockdowns.list[gid] = lockdown
+	p.lockdowns.Unlock()
+
+	for _, protectionType := range protectionTypes {
+		isAlready, err := p.setProtection(gid, protectionType, true)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		lockdown.Previous[protectionType] = isAlready
+	}
+	if err := p.saveLockdown(gid, lockdown); err != nil {
+		log.Println("error:", err.Error())
+	}
+
+	cl := p.Utils.GetRandomClient()
+	group, err := cl.GetGroup(p.Ctx, gid)
+	if err != nil {
+		log.Println("error:", err.Error())
+	} else {
+		if !group.PreventedJoinByTicket {
+			group.PreventedJoinByTicket = true
+			if err := cl.UpdateGroup(p.Ctx, 0, group); err != nil {
+				log.Println("error:", err.Error())
+			}
+		}
+		cancelled := p.cancelAllInvitations(gid, group)
+		p.AddLockdownCancelled(gid, cancelled)
+	}
+
+	p.lockdowns.Lock()
+	lockdown.timer = time.AfterFunc(time.Until(until), func() {
+		p.endLockdown(gid)
+	})
+	cancelled := lockdown.Cancelled
+	p.lockdowns.Unlock()
+
+	p.Utils.ReplyLocalized(p.Ctx, message, "lockdown.started", cancelled, until.Format("15:04"))
+}
+
+func (p *CommandProcessor) saveLockdown(gid string, lockdown *Lockdown) error {
+	enabled := []string{}
+	for _, protectionType := range protectionTypes {
+		if isAlready, ok := lockdown.Previous[protectionType]; ok && !isAlready {
+			enabled = append(enabled, protectionType)
+		}
+	}
+	_, err := p.DB.Exec(
+		`INSERT INTO lockdowns(gid, until, enabled, cancelled, kicked) VALUES (?, ?, ?, 0, 0)
+		ON DUPLICATE KEY UPDATE until = VALUES(until), enabled = VALUES(enabled), cancelled = 0, kicked = 0`,
+		gid, lockdown.Until, strings.Join(enabled, ","),
+	)
+	return err
+}
+
+func (p *CommandProcessor) RestoreLockdowns() {
+	rows, err := p.DB.Query(`SELECT gid, until, enabled, cancelled, kicked FROM lockdowns`)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return
+	}
+	gids := []string{}
+	p.lockdowns.Lock()
+	for rows.Next() {
+		var gid, enabled string
+		var until mysql.NullTime
+		lockdown := &Lockdown{Previous: map[string]bool{}}
+		if err := rows.Scan(&gid, &until, &enabled, &lockdown.Cancelled, &lockdown.Kicked); err != nil {
+			log.Println("error:", err.Error())
+			break
+		}
+		lockdown.Until = until.Time
+		for _, protectionType := range strings.Split(enabled, ",") {
+			if protectionType != "" {
+				lockdown.Previous[protectionType] = false
+			}
+		}
+		p.lockdowns.list[gid] = lockdown
+		gids = append(gids, gid)
+	}
+	p.lockdowns.Unlock()
+	rows.Close()
+
+	for _, gid := range gids {
+		gid := gid
+		p.lockdowns.Lock()
+		lockdown := p.lockdowns.list[gid]
+		if remaining := time.Until(lockdown.Until); remaining > 0 {
+			lockdown.timer = time.AfterFunc(remaining, func() {
+				p.endLockdown(gid)
+			})
+			p.lockdowns.Unlock()
+			log.Printf("info: Lockdown resumed -> %s(%s)\n", gid, lockdown.Until.Format("15:04"))
+			continue
+		}
+		p.lockdowns.Unlock()
+		p.endLockdown(gid)
+	}
+}
+
+func (p *CommandProcessor) StopLockdown(message *linethrift.Message) {
+	p.lockdowns.Lock()
+	lockdown, ok := p.lockdowns.list[message.To]
+	if ok && lockdown.timer != nil {
+		lockdown.timer.Stop()
+	}
+	p.lockdowns.Unlock()
+	if !ok {
+		p.Utils.ReplyLocalized(p.Ctx, message, "lockdown.notactive")
+		return
+	}
+	p.endLockdown(message.To)
+}
+
+func (p *CommandProcessor) IsLockdown(gid string) bool {
+	p.lockdowns.Lock()
+	defer p.lockdowns.Unlock()
+	_, ok := p.lockdowns.list[gid]
+	return ok
+}
+
+func (p *CommandProcessor) AddLockdownCancelled(gid string, n int) {
+	p.lockdowns.Lock()
+	lockdown, ok := p.lockdowns.list[gid]
+	if ok {
+		lockdown.Cancelled += n
+	}
+	p.lockdowns.Unlock()
+	if ok {
+		p.addLockdownCount(gid, "cancelled", n)
+	}
+}
+
+func (p *CommandProcessor) AddLockdownKicked(gid string, n int) {
+	p.lockdowns.Lock()
+	lockdown, ok := p.lockdowns.list[gid]
+	if ok {
+		lockdown.Kicked += n
+	}
+	p.lockdowns.Unlock()
+	if ok {
+		p.addLockdownCount(gid, "kicked", n)
+	}
+}
+
+func (p *CommandProcessor) addLockdownCount(gid string, column string, n int) {
+	_, err := p.DB.Exec(
+		`UPDATE lockdowns SET `+column+` = `+column+` + ? WHERE gid = ?`,
+		n, gid,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+	}
+}
+
+func (p *CommandProcessor) cancelAllInvitations(gid string, group *linethrift.Group) int {
+	targets := []string{}
+	for _, invitee := range group.Invitee {
+		if !p.Utils.IsBotMid(invitee.Mid) {
+			targets = append(targets, invitee.Mid)
+		}
+	}
+	cancelled := 0
+	for i, target := range targets {
+		cl := p.Utils.Client[i%len(p.Utils.Client)]
+		err := cl.CancelGroupInvitation(p.Ctx, 0, gid, []string{target})
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		cancelled++
+		if (i+1)%len(p.Utils.Client) == 0 {
+			time.Sleep(time.Millisecond * 500)
+		}
+	}
+	return cancelled
+}
+
+func (p *CommandProcessor) endLockdown(gid string) {
+	p.lockdowns.Lock()
+	lockdown, ok := p.lockdowns.list[gid]
+	delete(p.lockdowns.list, gid)
+	p.lockdowns.Unlock()
+	if !ok {
+		return
+	}
+	if _, err := p.DB.Exec(`DELETE FROM lockdowns WHERE gid = ?`, gid); err != nil {
+		log.Println("error:", err.Error())
+	}
+
+	restored := 0
+	for _, protectionType := range protectionTypes {
+		if isAlready, ok := lockdown.Previous[protectionType]; !ok || isAlready {
+			continue
+		}
+		if _, err := p.setProtection(gid, protectionType, false); err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		restored++
+	}
+
+	text := p.Utils.T(gid, "lockdown.ended", lockdown.Cancelled, lockdown.Kicked)
+	if restored > 0 {
+		text += "\n\n" + p.Utils.T(gid, "lockdown.restored")
+	}
+	p.Utils.SendMessageWithRandomClient(p.Ctx, gid, text)
+}
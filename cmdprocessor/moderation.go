@@ -0,0 +1,188 @@
+package cmdprocessor
+
+import (
+	"log"
+	"strings"
+
+	"../pendinginput"
+	"github.com/mopeneko/linethrift"
+)
+
+func (p *CommandProcessor) getDisplayName(gid string, mid string) string {
+	contact, err := p.Utils.Client[0].GetContact(p.Ctx, mid)
+	if err != nil {
+		return p.Utils.T(gid, "account.deleted")
+	}
+	return contact.DisplayName
+}
+
+func (p *CommandProcessor) joinDisplayNames(gid string, mids []string) string {
+	names := make([]string, len(mids))
+	for i, mid := range mids {
+		names[i] = p.getDisplayName(gid, mid)
+	}
+	return strings.Join(names, p.Utils.T(gid, "common.separator"))
+}
+
+func (p *CommandProcessor) filterModerationTargets(gid string, targets []string) ([]string, []string) {
+	valid := []string{}
+	skipped := []string{}
+	for _, target := range targets {
+		if p.Utils.IsBotMid(target) {
+			skipped = append(skipped, target)
+			continue
+		}
+		if ok, _ := p.Utils.HasGroupPermission(gid, target); ok {
+			skipped = append(skipped, target)
+			continue
+		}
+		valid = append(valid, target)
+	}
+	return valid, skipped
+}
+
+func (p *CommandProcessor) kickMembers(gid string, targets []string) []string {
+	kicked := []string{}
+	for _, target := range targets {
+		err := p.Utils.GetRandomKicker().KickoutFromGroup(p.Ctx, 0, gid, []string{target})
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		kicked = append(kicked, target)
+	}
+	return kicked
+}
+
+func (p *CommandProcessor) WaitForTargets(message *linethrift.Message, handler func(*linethrift.Message, []string)) {
+	p.waitForInput(
+		message,
+		pendinginput.INPUT_CONTACT|pendinginput.INPUT_MENTION,
+		"remote.prompt",
+		func(input *pendinginput.Input) {
+			handler(message, inputMids(input))
+		},
+	)
+}
+
+func (p *CommandProcessor) sendModerationResult(message *linethrift.Message, done []string, doneKey string, skipped []string) {
+	gid := message.To
+	text := ""
+	if len(done) > 0 {
+		text = p.Utils.T(gid, doneKey, p.joinDisplayNames(gid, done))
+	}
+	if len(skipped) > 0 {
+		if text != "" {
+			text += "\n"
+		}
+		text += p.Utils.T(gid, "moderation.skipped", p.joinDisplayNames(gid, skipped))
+	}
+	if text == "" {
+		text = p.Utils.T(gid, "moderation.nothing")
+	}
+	p.Utils.Reply(p.Ctx, message, text)
+}
+
+func (p *CommandProcessor) KickMembers(message *linethrift.Message, targets []string) {
+	valid, skipped := p.filterModerationTargets(message.To, targets)
+	kicked := p.kickMembers(message.To, valid)
+	p.sendModerationResult(message, kicked, "moderation.kicked", skipped)
+}
+
+func (p *CommandProcessor) BanMembers(message *linethrift.Message, targets []string) {
+	valid, skipped := p.filterModerationTargets(message.To, targets)
+	banned := []string{}
+	for _, target := range valid {
+		_, err := p.DB.Exec(
+			`INSERT IGNORE INTO bans(gid, mid) VALUES (?, ?)`,
+			message.To, target,
+		)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		banned = append(banned, target)
+	}
+	p.kickMembers(message.To, banned)
+	p.sendModerationResult(message, banned, "moderation.banned", skipped)
+}
+
+func (p *CommandProcessor) UnbanMembers(message *linethrift.Message, targets []string) {
+	unbanned := []string{}
+	for _, target := range targets {
+		result, err := p.DB.Exec(
+			`DELETE FROM bans WHERE gid = ? AND mid = ?`,
+			message.To, target,
+		)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			unbanned = append(unbanned, target)
+		}
+	}
+	if len(unbanned) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "moderation.unban.empty")
+		return
+	}
+	p.sendModerationResult(message, unbanned, "moderation.unbanned", nil)
+}
+
+func (p *CommandProcessor) ProtectMembers(message *linethrift.Message, targets []string) {
+	protected := []string{}
+	for _, target := range targets {
+		if p.Utils.IsBotMid(target) {
+			continue
+		}
+		_, err := p.DB.Exec(
+			`INSERT IGNORE INTO protectedmembers(gid, mid) VALUES (?, ?)`,
+			message.To, target,
+		)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		protected = append(protected, target)
+	}
+	p.sendModerationResult(message, protected, "moderation.protected", nil)
+}
+
+func (p *CommandProcessor) UnprotectMembers(message *linethrift.Message, targets []string) {
+	unprotected := []string{}
+	for _, target := range targets {
+		result, err := p.DB.Exec(
+			`DELETE FROM protectedmembers WHERE gid = ? AND mid = ?`,
+			message.To, target,
+		)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			unprotected = append(unprotected, target)
+		}
+	}
+	if len(unprotected) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "moderation.unprotect.empty")
+		return
+	}
+	p.sendModerationResult(message, unprotected, "moderation.unprotected", nil)
+}
+
+func (p *CommandProcessor) PromoteMember(message *linethrift.Message, targets []string) {
+	if len(targets) != 1 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "subadmin.onlyone")
+		return
+	}
+	if p.Utils.IsBotMid(targets[0]) {
+		p.Utils.ReplyLocalized(p.Ctx, message, "subadmin.bot")
+		return
+	}
+	if err := p.SetSubAdmin(message.To, targets[0]); err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	p.Utils.ReplyLocalized(p.Ctx, message, "subadmin.changed", p.getDisplayName(message.To, targets[0]))
+}
@@ -0,0 +1,148 @@
+package cmdprocessor
+
+import (
+	"log"
+
+	cmd "../cmdconst"
+	"../utils"
+	"github.com/mopeneko/linethrift"
+)
+
+var planNames = map[string]string{
+	cmd.PLAN_BASIC:   utils.PLAN_BASIC,
+	cmd.PLAN_PRO:     utils.PLAN_PRO,
+	cmd.PLAN_PREMIUM: utils.PLAN_PREMIUM,
+}
+
+func (p *CommandProcessor) planName(chat string, id string) string {
+	if id == utils.PLAN_TRIAL {
+		return p.Utils.T(chat, "plan.trial")
+	}
+	for name, planID := range planNames {
+		if planID == id {
+			return cmd.LocalName(p.Utils.GetLanguage(chat), name)
+		}
+	}
+	return id
+}
+
+func (p *CommandProcessor) planList(chat string, ids []string) string {
+	text := ""
+	for i, id := range ids {
+		if i > 0 {
+			text += p.Utils.T(chat, "common.separator")
+		}
+		text += p.planName(chat, id)
+	}
+	return text
+}
+
+func (p *CommandProcessor) planSummary(chat string, plan *utils.Plan) string {
+	maxGroups := p.Utils.T(chat, "plan.unlimited")
+	if plan.MaxGroups > 0 {
+		maxGroups = p.Utils.T(chat, "plan.groups", plan.MaxGroups)
+	}
+	features := ""
+	for i, feature := range plan.Features {
+		if i > 0 {
+			features += p.Utils.T(chat, "common.separator")
+		}
+		features += p.Utils.T(chat, "feature."+string(feature))
+	}
+	return p.Utils.T(chat, "plan.summary", p.planName(chat, plan.ID), maxGroups, features)
+}
+
+func (p *CommandProcessor) featureDenied(chat string, plan *utils.Plan, feature utils.Feature) string {
+	return p.Utils.T(
+		chat, "plan.feature",
+		p.Utils.T(chat, "feature."+string(feature)),
+		p.planName(chat, plan.ID),
+		p.planList(chat, utils.PlansWith(feature)),
+	)
+}
+
+func (p *CommandProcessor) QuotaExceededText(chat string, plan *utils.Plan) string {
+	upgrades := []string{}
+	for _, id := range utils.PlanOrder {
+		if other := utils.Plans[id]; other.MaxGroups == 0 || other.MaxGroups > plan.MaxGroups {
+			upgrades = append(upgrades, id)
+		}
+	}
+	return p.Utils.T(chat, "plan.quota", p.planName(chat, plan.ID), plan.MaxGroups, p.planList(chat, upgrades))
+}
+
+func (p *CommandProcessor) TrialStartedText(mid string) string {
+	_, status, err := p.Utils.HasPermission(mid)
+	if err != nil {
+		log.Println("error:", err.Error())
+	}
+	return p.Utils.T(
+		mid, "trial.started",
+		utils.TRIAL_DAYS, status, p.planSummary(mid, utils.Plans[utils.PLAN_TRIAL]), utils.TICKET_PREFIX,
+	)
+}
+
+func (p *CommandProcessor) AllowFeature(message *linethrift.Message, feature utils.Feature) bool {
+	plan, err := p.Utils.GetGroupPlan(message.To)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return false
+	}
+	if plan.Allows(feature) {
+		return true
+	}
+	p.Utils.Reply(p.Ctx, message, p.featureDenied(message.To, plan, feature))
+	return false
+}
+
+func (p *CommandProcessor) filterSettings(gid string, settings map[string]bool) (map[string]bool, []string, error) {
+	plan, err := p.Utils.GetGroupPlan(gid)
+	if err != nil {
+		return nil, nil, err
+	}
+	allowed := map[string]bool{}
+	denied := []string{}
+	for _, protectionType := range protectionTypes {
+		isEnabled, ok := settings[protectionType]
+		if !ok {
+			continue
+		}
+		if isEnabled && !plan.Allows(utils.Feature(protectionType)) {
+			denied = append(denied, p.featureDenied(gid, plan, utils.Feature(protectionType)))
+			continue
+		}
+		allowed[protectionType] = isEnabled
+	}
+	return allowed, denied, nil
+}
+
+func (p *CommandProcessor) SetPlan(message *linethrift.Message, name string) {
+	id, ok := planNames[cmd.Resolve(name)]
+	if !ok {
+		language := p.Utils.GetLanguage(message.To)
+		p.Utils.ReplyLocalized(
+			p.Ctx, message, "plan.invalid",
+			cmd.LocalName(language, cmd.PLAN_BASIC),
+			cmd.LocalName(language, cmd.PLAN_PRO),
+			cmd.LocalName(language, cmd.PLAN_PREMIUM),
+		)
+		return
+	}
+	p.waitForContact(message, "plan.prompt", func(mid string) {
+		ok, err := p.Utils.SetPlan(mid, id)
+		if err != nil {
+			log.Println("error:", err.Error())
+			p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+			return
+		}
+		if !ok {
+			p.Utils.ReplyLocalized(p.Ctx, message, "permission.none")
+			return
+		}
+		p.Utils.ReplyLocalized(
+			p.Ctx, message, "plan.changed",
+			p.getDisplayName(message.To, mid), p.planSummary(message.To, utils.Plans[id]),
+		)
+	})
+}
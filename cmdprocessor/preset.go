@@ -0,0 +1,226 @@
+package cmdprocessor
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	cmd "../cmdconst"
+	"../i18n"
+	"github.com/mopeneko/linethrift"
+)
+
+var presets = map[string]map[string]bool{
+	cmd.PRESET_STANDARD: {
+		"name":   true,
+		"image":  true,
+		"url":    true,
+		"invite": false,
+	},
+	cmd.PRESET_STRICT: {
+		"name":   true,
+		"image":  true,
+		"url":    true,
+		"invite": true,
+	},
+	cmd.PRESET_OPEN: {
+		"name":   false,
+		"image":  false,
+		"url":    false,
+		"invite": false,
+	},
+}
+
+func (p *CommandProcessor) applySettings(gid string, settings map[string]bool) string {
+	settings, denied, err := p.filterSettings(gid, settings)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return p.Utils.T(gid, "setting.failed")
+	}
+	isAlready, err := p.setProtections(gid, settings)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return p.Utils.T(gid, "setting.failed")
+	}
+	results := []string{}
+	for _, protectionType := range protectionTypes {
+		isEnabled, ok := settings[protectionType]
+		if !ok {
+			continue
+		}
+		p.rememberManualSetting(gid, protectionType, isEnabled)
+		results = append(results, p.buildSettingResultText(gid, protectionType, isAlready[protectionType], isEnabled))
+	}
+	results = append(results, denied...)
+	return strings.Join(results, "\n")
+}
+
+func IsPreset(name string) bool {
+	_, ok := presets[cmd.Resolve(name)]
+	return ok
+}
+
+func (p *CommandProcessor) SetPreset(gid string, name string) (string, bool) {
+	settings, ok := presets[cmd.Resolve(name)]
+	if !ok {
+		return "", false
+	}
+	return p.applySettings(gid, settings), true
+}
+
+func (p *CommandProcessor) ApplyPreset(message *linethrift.Message, name string) {
+	result, ok := p.SetPreset(message.To, name)
+	language := p.Utils.GetLanguage(message.To)
+	if !ok {
+		p.Utils.ReplyLocalized(
+			p.Ctx, message, "preset.unknown",
+			cmd.LocalName(language, cmd.PRESET_STANDARD),
+			cmd.LocalName(language, cmd.PRESET_STRICT),
+			cmd.LocalName(language, cmd.PRESET_OPEN),
+		)
+		return
+	}
+	p.Utils.ReplyLocalized(p.Ctx, message, "preset.applied", cmd.LocalName(language, cmd.Resolve(name)), result)
+}
+
+func (p *CommandProcessor) getTemplateGroups(gid string, inviter string) ([]string, error) {
+	rows, err := p.DB.Query(
+		`SELECT id FROM protections WHERE inviter = ? AND id != ? ORDER BY id`,
+		inviter, gid,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	gids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		gids = append(gids, id)
+	}
+	return gids, rows.Err()
+}
+
+func (p *CommandProcessor) ListTemplates(message *linethrift.Message) {
+	gids, err := p.getTemplateGroups(message.To, message.From)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if len(gids) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "template.empty")
+		return
+	}
+	text := p.Utils.T(message.To, "template.list")
+	for i, gid := range gids {
+		name := p.Utils.T(message.To, "group.unknown")
+		group, err := p.Utils.Client[0].GetGroupWithoutMembers(p.Ctx, gid)
+		if err == nil {
+			name = group.Name
+		}
+		text += fmt.Sprintf("\n%d. %s", i+1, name)
+	}
+	language := p.Utils.GetLanguage(message.To)
+	text += "\n\n" + p.Utils.T(
+		message.To, "template.hint",
+		i18n.T(language, "prefix.setting")+cmd.LocalName(language, cmd.SETTING_COPY),
+	)
+	p.Utils.Reply(p.Ctx, message, text)
+}
+
+func (p *CommandProcessor) CopySettings(message *linethrift.Message, indexText string) {
+	gids, err := p.getTemplateGroups(message.To, message.From)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	index, err := strconv.Atoi(indexText)
+	if err != nil || index < 1 || index > len(gids) {
+		language := p.Utils.GetLanguage(message.To)
+		p.Utils.ReplyLocalized(
+			p.Ctx, message, "template.invalid",
+			i18n.T(language, "prefix.setting")+cmd.LocalName(language, cmd.SETTING_TEMPLATES),
+		)
+		return
+	}
+	source := gids[index-1]
+
+	settings, err := p.getProtections(source)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	result := p.applySettings(message.To, settings)
+
+	if err := p.copyRoles(source, message.To); err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "copy.failed", result)
+		return
+	}
+
+	p.Utils.ReplyLocalized(p.Ctx, message, "copy.done", result)
+}
+
+func (p *CommandProcessor) copyRoles(source string, gid string) error {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return err
+	}
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{
+			`UPDATE protections AS target, protections AS source
+			SET target.subadmin = source.subadmin
+			WHERE target.id = ? AND source.id = ?`,
+			[]interface{}{gid, source},
+		},
+		{
+			`DELETE FROM trustedinviters WHERE gid = ?`,
+			[]interface{}{gid},
+		},
+		{
+			`INSERT INTO trustedinviters(gid, mid) SELECT ?, mid FROM trustedinviters WHERE gid = ?`,
+			[]interface{}{gid, source},
+		},
+		{
+			`DELETE FROM bans WHERE gid = ?`,
+			[]interface{}{gid},
+		},
+		{
+			`INSERT INTO bans(gid, mid) SELECT ?, mid FROM bans WHERE gid = ?`,
+			[]interface{}{gid, source},
+		},
+		{
+			`DELETE FROM protectedmembers WHERE gid = ?`,
+			[]interface{}{gid},
+		},
+		{
+			`INSERT INTO protectedmembers(gid, mid) SELECT ?, mid FROM protectedmembers WHERE gid = ?`,
+			[]interface{}{gid, source},
+		},
+		{
+			`DELETE FROM schedules WHERE gid = ?`,
+			[]interface{}{gid},
+		},
+		{
+			`INSERT INTO schedules(gid, protection, startminute, endminute, startdate, enddate, active)
+			SELECT ?, protection, startminute, endminute, startdate, enddate, FALSE FROM schedules WHERE gid = ?`,
+			[]interface{}{gid, source},
+		},
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement.query, statement.args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
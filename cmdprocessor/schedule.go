@@ -0,0 +1,340 @@
+package cmdprocessor
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+
+	cmd "../cmdconst"
+	"../utils"
+	"github.com/go-sql-driver/mysql"
+	"github.com/mopeneko/linethrift"
+)
+
+var settingProtectionTypes = map[string]string{
+	cmd.SETTING_NAME:   "name",
+	cmd.SETTING_ICON:   "image",
+	cmd.SETTING_URL:    "url",
+	cmd.SETTING_INVITE: "invite",
+}
+
+var (
+	dailyRangePattern = regexp.MustCompile(`^(\d{1,2}):?(\d{2})-(\d{1,2}):?(\d{2})$`)
+	dateRangePattern  = regexp.MustCompile(`^(\d{4}/\d{1,2}/\d{1,2})-(\d{4}/\d{1,2}/\d{1,2})$`)
+)
+
+type Schedule struct {
+	ID          int64
+	Gid         string
+	Protection  string
+	StartMinute sql.NullInt64
+	EndMinute   sql.NullInt64
+	StartDate   mysql.NullTime
+	EndDate     mysql.NullTime
+	Active      bool
+	Previous    sql.NullBool
+}
+
+func parseSchedule(text string) (*Schedule, error) {
+	JST, _ := time.LoadLocation("Asia/Tokyo")
+	if m := dailyRangePattern.FindStringSubmatch(text); m != nil {
+		minutes := [2]int64{}
+		for i := 0; i < 2; i++ {
+			hour, _ := strconv.Atoi(m[i*2+1])
+			minute, _ := strconv.Atoi(m[i*2+2])
+			if hour > 23 || minute > 59 {
+				return nil, errors.New("Time is out of range.")
+			}
+			minutes[i] = int64(hour*60 + minute)
+		}
+		if minutes[0] == minutes[1] {
+			return nil, errors.New("Start and end are the same.")
+		}
+		return &Schedule{
+			StartMinute: sql.NullInt64{Int64: minutes[0], Valid: true},
+			EndMinute:   sql.NullInt64{Int64: minutes[1], Valid: true},
+		}, nil
+	}
+	if m := dateRangePattern.FindStringSubmatch(text); m != nil {
+		start, err := time.ParseInLocation("2006/1/2", m[1], JST)
+		if err != nil {
+			return nil, err
+		}
+		end, err := time.ParseInLocation("2006/1/2", m[2], JST)
+		if err != nil {
+			return nil, err
+		}
+		if end.Before(start) {
+			return nil, errors.New("End is before start.")
+		}
+		return &Schedule{
+			StartDate: mysql.NullTime{Time: start, Valid: true},
+			EndDate:   mysql.NullTime{Time: end.AddDate(0, 0, 1), Valid: true},
+		}, nil
+	}
+	return nil, errors.New("Schedule format is wrong.")
+}
+
+func (s *Schedule) isActive(now time.Time) bool {
+	if s.StartDate.Valid {
+		return !now.Before(s.StartDate.Time) && now.Before(s.EndDate.Time)
+	}
+	minute := int64(now.Hour()*60 + now.Minute())
+	if s.StartMinute.Int64 < s.EndMinute.Int64 {
+		return minute >= s.StartMinute.Int64 && minute < s.EndMinute.Int64
+	}
+	return minute >= s.StartMinute.Int64 || minute < s.EndMinute.Int64
+}
+
+func (s *Schedule) Format(name string) string {
+	if s.StartDate.Valid {
+		return fmt.Sprintf(
+			"%d. %s %s-%s",
+			s.ID, name,
+			s.StartDate.Time.Format("2006/01/02"),
+			s.EndDate.Time.AddDate(0, 0, -1).Format("2006/01/02"),
+		)
+	}
+	return fmt.Sprintf(
+		"%d. %s %02d:%02d-%02d:%02d",
+		s.ID, name,
+		s.StartMinute.Int64/60, s.StartMinute.Int64%60,
+		s.EndMinute.Int64/60, s.EndMinute.Int64%60,
+	)
+}
+
+func (p *CommandProcessor) formatSchedule(gid string, schedule *Schedule) string {
+	return schedule.Format(p.protectionName(gid, schedule.Protection))
+}
+
+func (p *CommandProcessor) getSchedules(query string, args ...interface{}) ([]*Schedule, error) {
+	rows, err := p.DB.Query(
+		`SELECT id, gid, protection, startminute, endminute, startdate, enddate, active, previous
+		FROM schedules `+query,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schedules := []*Schedule{}
+	for rows.Next() {
+		s := &Schedule{}
+		err := rows.Scan(
+			&s.ID, &s.Gid, &s.Protection,
+			&s.StartMinute, &s.EndMinute,
+			&s.StartDate, &s.EndDate,
+			&s.Active, &s.Previous,
+		)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+func (p *CommandProcessor) AddSchedule(message *linethrift.Message, setting string, rangeText string) {
+	protectionType, ok := settingProtectionTypes[cmd.Resolve(setting)]
+	if !ok {
+		p.Utils.ReplyLocalized(p.Ctx, message, "schedule.unknown")
+		return
+	}
+	if !p.AllowFeature(message, utils.Feature(protectionType)) {
+		return
+	}
+	schedule, err := parseSchedule(rangeText)
+	if err != nil {
+		p.Utils.ReplyLocalized(p.Ctx, message, "schedule.invalid")
+		return
+	}
+	result, err := p.DB.Exec(
+		`INSERT INTO schedules(gid, protection, startminute, endminute, startdate, enddate, active)
+		VALUES (?, ?, ?, ?, ?, ?, FALSE)`,
+		message.To, protectionType,
+		schedule.StartMinute, schedule.EndMinute,
+		schedule.StartDate, schedule.EndDate,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	schedule.ID, _ = result.LastInsertId()
+	schedule.Protection = protectionType
+	p.Utils.ReplyLocalized(p.Ctx, message, "schedule.added", p.formatSchedule(message.To, schedule))
+}
+
+func (p *CommandProcessor) RemoveSchedule(message *linethrift.Message, idText string) {
+	id, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		p.Utils.ReplyLocalized(p.Ctx, message, "schedule.id")
+		return
+	}
+	schedules, err := p.getSchedules(`WHERE id = ? AND gid = ?`, id, message.To)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if len(schedules) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "schedule.notfound")
+		return
+	}
+	_, err = p.DB.Exec(`DELETE FROM schedules WHERE id = ?`, id)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	text := p.Utils.T(message.To, "schedule.removed", p.formatSchedule(message.To, schedules[0]))
+	if restored := p.restoreRemovedSchedule(schedules[0]); restored != "" {
+		text += "\n" + restored
+	}
+	p.Utils.Reply(p.Ctx, message, text)
+}
+
+func (p *CommandProcessor) restoreRemovedSchedule(schedule *Schedule) string {
+	if !schedule.Active || !schedule.Previous.Valid {
+		return ""
+	}
+	var isStillActive bool
+	err := p.DB.QueryRow(
+		`SELECT exists(SELECT 1 FROM schedules WHERE gid = ? AND protection = ? AND active = TRUE)`,
+		schedule.Gid, schedule.Protection,
+	).Scan(&isStillActive)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return ""
+	}
+	if isStillActive || p.IsLockdown(schedule.Gid) {
+		return ""
+	}
+	isAlready, err := p.setProtection(schedule.Gid, schedule.Protection, schedule.Previous.Bool)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return p.Utils.T(schedule.Gid, "setting.failed")
+	}
+	if isAlready {
+		return ""
+	}
+	return p.buildSettingResultText(schedule.Gid, schedule.Protection, false, schedule.Previous.Bool)
+}
+
+func (p *CommandProcessor) rememberManualSetting(gid string, protectionType string, isEnabled bool) {
+	_, err := p.DB.Exec(
+		`UPDATE schedules SET previous = ? WHERE gid = ? AND protection = ? AND active = TRUE`,
+		isEnabled, gid, protectionType,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+	}
+}
+
+func (p *CommandProcessor) buildScheduleText(gid string) string {
+	schedules, err := p.getSchedules(`WHERE gid = ? ORDER BY id`, gid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return ""
+	}
+	if len(schedules) == 0 {
+		return ""
+	}
+	text := "\n\n" + p.Utils.T(gid, "schedule.list")
+	for _, schedule := range schedules {
+		text += "\n" + p.formatSchedule(gid, schedule)
+	}
+	return text
+}
+
+func (p *CommandProcessor) ApplySchedules() error {
+	schedules, err := p.getSchedules(`ORDER BY gid, protection`)
+	if err != nil {
+		return err
+	}
+	JST, _ := time.LoadLocation("Asia/Tokyo")
+	now := time.Now().In(JST)
+
+	type key struct{ gid, protection string }
+	wasActive := map[key]bool{}
+	isActive := map[key]bool{}
+	previous := map[key]sql.NullBool{}
+	for _, schedule := range schedules {
+		k := key{schedule.Gid, schedule.Protection}
+		wasActive[k] = wasActive[k] || schedule.Active
+		isActive[k] = isActive[k] || schedule.isActive(now)
+		if schedule.Active && schedule.Previous.Valid {
+			previous[k] = schedule.Previous
+		}
+	}
+
+	var failed error
+	skipped := map[string]bool{}
+	for k, active := range isActive {
+		if active == wasActive[k] {
+			continue
+		}
+		if p.IsLockdown(k.gid) {
+			skipped[k.gid] = true
+			continue
+		}
+		isEnabled := true
+		if active {
+			current, err := p.getProtections(k.gid)
+			if err != nil {
+				log.Printf("error: %s | %s", k.gid, err.Error())
+				failed = err
+				skipped[k.gid] = true
+				continue
+			}
+			previous[k] = sql.NullBool{Bool: current[k.protection], Valid: true}
+		} else {
+			isEnabled = previous[k].Bool
+		}
+		isAlready, err := p.setProtection(k.gid, k.protection, isEnabled)
+		if err != nil {
+			log.Printf("error: %s | %s", k.gid, err.Error())
+			failed = err
+			skipped[k.gid] = true
+			continue
+		}
+		if !isAlready {
+			p.Utils.SendLocalizedMessage(
+				p.Ctx, k.gid, "schedule.applied",
+				p.buildSettingResultText(k.gid, k.protection, false, isEnabled),
+			)
+		}
+	}
+
+	for _, schedule := range schedules {
+		active := schedule.isActive(now)
+		if active == schedule.Active || skipped[schedule.Gid] {
+			continue
+		}
+		state := sql.NullBool{}
+		if active {
+			state = previous[key{schedule.Gid, schedule.Protection}]
+		}
+		_, err := p.DB.Exec(
+			`UPDATE schedules SET active = ?, previous = ? WHERE id = ?`,
+			active, state, schedule.ID,
+		)
+		if err != nil {
+			log.Println("error:", err.Error())
+			failed = err
+		}
+	}
+
+	_, err = p.DB.Exec(
+		`DELETE FROM schedules WHERE enddate < ? AND active = FALSE`,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+	return failed
+}
@@ -0,0 +1,203 @@
+package cmdregistry
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	cmd "../cmdconst"
+	"../i18n"
+	"github.com/mopeneko/linethrift"
+)
+
+type Family uint
+
+const (
+	FAMILY_NORMAL Family = 1 << iota
+	FAMILY_SETTING
+	FAMILY_DIRECT
+)
+
+type Role int
+
+const (
+	ROLE_EVERYONE Role = iota
+	ROLE_GROUPADMIN
+	ROLE_INVITER
+	ROLE_ADMIN
+)
+
+type ArgType int
+
+const (
+	ARG_STRING ArgType = iota
+	ARG_SWITCH
+	ARG_INT
+)
+
+type Arg struct {
+	Name     string
+	Type     ArgType
+	Optional bool
+	Rest     bool
+}
+
+type ArgError struct {
+	Arg    string
+	Reason string
+}
+
+func (e *ArgError) Error() string {
+	return e.Localize(i18n.DEFAULT_LANGUAGE)
+}
+
+func (e *ArgError) Localize(language string) string {
+	if e.Arg == "" {
+		return i18n.T(language, e.Reason)
+	}
+	return i18n.T(language, e.Reason, i18n.T(language, e.Arg))
+}
+
+type Handler func(message *linethrift.Message, args []string)
+
+type Command struct {
+	Name    string
+	Aliases []string
+	Family  Family
+	Args    []Arg
+	Role    Role
+	Feature string
+	Help    string
+	Handler Handler
+}
+
+type Registry struct {
+	commands []*Command
+	index    map[Family]map[string]*Command
+}
+
+func New() *Registry {
+	return &Registry{
+		commands: []*Command{},
+		index: map[Family]map[string]*Command{
+			FAMILY_NORMAL:  {},
+			FAMILY_SETTING: {},
+			FAMILY_DIRECT:  {},
+		},
+	}
+}
+
+func (r *Registry) Register(command *Command) {
+	names := append([]string{command.Name}, command.Aliases...)
+	for family, index := range r.index {
+		if command.Family&family == 0 {
+			continue
+		}
+		for _, name := range names {
+			key := strings.ToLower(name)
+			if _, ok := index[key]; ok {
+				panic("cmdregistry: duplicate command " + name)
+			}
+			index[key] = command
+		}
+	}
+	r.commands = append(r.commands, command)
+}
+
+func (r *Registry) Lookup(family Family, name string) (*Command, bool) {
+	command, ok := r.index[family][strings.ToLower(name)]
+	return command, ok
+}
+
+func (r *Registry) Commands(family Family) []*Command {
+	commands := []*Command{}
+	for _, command := range r.commands {
+		if command.Family&family != 0 {
+			commands = append(commands, command)
+		}
+	}
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].Role < commands[j].Role
+	})
+	return commands
+}
+
+func (c *Command) MatchArgs(args []string) bool {
+	required := 0
+	for _, arg := range c.Args {
+		if arg.Rest {
+			return len(args) >= required+1 || (arg.Optional && len(args) >= required)
+		}
+		if !arg.Optional {
+			required++
+		}
+	}
+	return len(args) >= required && len(args) <= len(c.Args)
+}
+
+func (c *Command) Usage(prefix string, language string) string {
+	usage := prefix + cmd.LocalName(language, c.Name)
+	for _, arg := range c.Args {
+		name := i18n.T(language, arg.Name)
+		if arg.Optional {
+			usage += "[:" + name + "]"
+		} else {
+			usage += ":" + name
+		}
+	}
+	return usage
+}
+
+func (c *Command) JoinRest(args []string) []string {
+	if len(c.Args) == 0 || !c.Args[len(c.Args)-1].Rest || len(args) < len(c.Args) {
+		return args
+	}
+	n := len(c.Args) - 1
+	return append(args[:n:n], strings.Join(args[n:], ":"))
+}
+
+func ParseSwitch(text string) (bool, bool) {
+	switch strings.ToLower(text) {
+	case "オン", "on":
+		return true, true
+	case "オフ", "off":
+		return false, true
+	}
+	return false, false
+}
+
+func (c *Command) ParseArgs(args []string) ([]string, error) {
+	if !c.MatchArgs(args) {
+		return nil, &ArgError{"", "arg.error.count"}
+	}
+	args = c.JoinRest(args)
+	parsed := make([]string, len(args))
+	for i, value := range args {
+		arg := c.Args[i]
+		switch arg.Type {
+		case ARG_SWITCH:
+			isEnabled, ok := ParseSwitch(value)
+			if !ok {
+				return nil, &ArgError{arg.Name, "arg.error.switch"}
+			}
+			parsed[i] = "オフ"
+			if isEnabled {
+				parsed[i] = "オン"
+			}
+		case ARG_INT:
+			value = strings.Map(func(r rune) rune {
+				if r >= '０' && r <= '９' {
+					return r - '０' + '0'
+				}
+				return r
+			}, value)
+			if _, err := strconv.Atoi(value); err != nil {
+				return nil, &ArgError{arg.Name, "arg.error.int"}
+			}
+			parsed[i] = value
+		default:
+			parsed[i] = value
+		}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,96 @@
+package cmdregistry
+
+import (
+	"sort"
+	"strings"
+
+	cmd "../cmdconst"
+)
+
+const MAX_SUGGESTIONS = 3
+
+type Suggestion struct {
+	Command  *Command
+	Name     string
+	Distance int
+}
+
+func distance(a []rune, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func maxDistance(name []rune) int {
+	if len(name) <= 3 {
+		return 1
+	}
+	if len(name) <= 6 {
+		return 2
+	}
+	return 3
+}
+
+func (r *Registry) Suggest(family Family, name string, language string, filter func(*Command) bool) []Suggestion {
+	target := []rune(strings.ToLower(name))
+	limit := maxDistance(target)
+	best := map[*Command]Suggestion{}
+	for key, command := range r.index[family] {
+		if key != strings.ToLower(cmd.LocalName(language, command.Name)) {
+			continue
+		}
+		if filter != nil && !filter(command) {
+			continue
+		}
+		d := distance(target, []rune(key))
+		if d > limit {
+			continue
+		}
+		if s, ok := best[command]; ok && s.Distance <= d {
+			continue
+		}
+		best[command] = Suggestion{command, key, d}
+	}
+	suggestions := []Suggestion{}
+	for _, s := range best {
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Distance != suggestions[j].Distance {
+			return suggestions[i].Distance < suggestions[j].Distance
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+	if len(suggestions) > MAX_SUGGESTIONS {
+		suggestions = suggestions[:MAX_SUGGESTIONS]
+	}
+	return suggestions
+}
@@ -0,0 +1,103 @@
+package cmdregistry
+
+import (
+	"reflect"
+	"testing"
+
+	cmd "../cmdconst"
+	"../i18n"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"kick", "kick", 0},
+		{"", "kick", 4},
+		{"kick", "", 4},
+		{"kick", "kik", 1},
+		{"kik", "kick", 1},
+		{"kick", "kack", 1},
+		{"kcik", "kick", 1},
+		{"lockdwon", "lockdown", 1},
+		{"lockdwn", "unlockdown", 3},
+		{"ロックダン", "ロックダウン", 1},
+		{"ロクッダウン", "ロックダウン", 1},
+		{"確認", "言語", 2},
+	}
+	for _, tt := range tests {
+		if d := distance([]rune(tt.a), []rune(tt.b)); d != tt.distance {
+			t.Errorf("distance(%q, %q) = %d, want %d", tt.a, tt.b, d, tt.distance)
+		}
+	}
+}
+
+func newSuggestRegistry() *Registry {
+	r := New()
+	for _, command := range []*Command{
+		{Name: cmd.SETTING_LOCKDOWN, Role: ROLE_GROUPADMIN},
+		{Name: cmd.SETTING_UNLOCKDOWN, Role: ROLE_GROUPADMIN},
+		{Name: cmd.SETTING_CHECK, Role: ROLE_EVERYONE},
+		{Name: cmd.SETTING_COPY, Role: ROLE_INVITER},
+		{Name: "abcd"},
+		{Name: "abce"},
+		{Name: "abcf"},
+		{Name: "abcg"},
+	} {
+		if english, ok := cmd.ENGLISH_NAMES[command.Name]; ok {
+			command.Aliases = append(command.Aliases, english)
+		}
+		command.Family = FAMILY_SETTING
+		r.Register(command)
+	}
+	return r
+}
+
+func TestSuggest(t *testing.T) {
+	r := newSuggestRegistry()
+	adminOnly := func(command *Command) bool { return command.Role != ROLE_GROUPADMIN }
+	tests := []struct {
+		name     string
+		input    string
+		language string
+		filter   func(*Command) bool
+		names    []string
+	}{
+		{"closest first", "lockdwn", i18n.LANG_EN, nil, []string{"lockdown", "unlockdown"}},
+		{"case insensitive", "LOCKDWON", i18n.LANG_EN, nil, []string{"lockdown", "unlockdown"}},
+		{"japanese names in japanese groups", "ロックダン", i18n.LANG_JA, nil, []string{cmd.SETTING_LOCKDOWN}},
+		{"no english names in japanese groups", "lockdwn", i18n.LANG_JA, nil, []string{}},
+		{"no japanese names in english groups", "ロックダン", i18n.LANG_EN, nil, []string{}},
+		{"role filter", "lockdwn", i18n.LANG_EN, adminOnly, []string{}},
+		{"ties sorted by name and capped", "abch", i18n.LANG_EN, nil, []string{"abcd", "abce", "abcf"}},
+		{"names without translation in english groups", "abdc", i18n.LANG_EN, nil, []string{"abcd", "abce", "abcf"}},
+		{"too far", "kick", i18n.LANG_EN, nil, []string{}},
+		{"short names allow one edit", "cpy", i18n.LANG_EN, nil, []string{"copy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := []string{}
+			for _, s := range r.Suggest(FAMILY_SETTING, tt.input, tt.language, tt.filter) {
+				names = append(names, s.Name)
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("Suggest(%q) = %q, want %q", tt.input, names, tt.names)
+			}
+		})
+	}
+}
+
+func TestSuggestDistanceOrder(t *testing.T) {
+	r := newSuggestRegistry()
+	suggestions := r.Suggest(FAMILY_SETTING, "abcdx", i18n.LANG_EN, nil)
+	for i := 1; i < len(suggestions); i++ {
+		if suggestions[i-1].Distance > suggestions[i].Distance {
+			t.Fatalf("suggestions are not ordered by distance: %+v", suggestions)
+		}
+	}
+	if len(suggestions) == 0 || suggestions[0].Name != "abcd" || suggestions[0].Distance != 1 {
+		t.Errorf("first suggestion = %+v, want abcd at distance 1", suggestions)
+	}
+}
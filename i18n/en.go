@@ -0,0 +1,325 @@
+package i18n
+
+var en = map[string]string{
+	"greeting": "Authentication complete!\n" +
+		"Now, let's get started!\n\n" +
+		"*Lines are inspired by the system voice of beatmania IIDX 26 Rootage.\n\n" +
+		"[Author]\n" +
+		"のえる\n" +
+		"http://line.me/ti/p/%40djv5227g\n\n" +
+		"*This bot is unofficial.",
+
+	"prefix.normal":  "tamaki:",
+	"prefix.setting": "setting:",
+
+	"common.none":      "none",
+	"common.separator": ", ",
+	"account.deleted":  "deleted account",
+	"group.unknown":    "unknown group",
+	"switch.on":        "on",
+	"switch.off":       "off",
+
+	"protection.name":   "Group name lock",
+	"protection.image":  "Icon lock",
+	"protection.url":    "Invite link block",
+	"protection.invite": "Invite block",
+
+	"error.generic": "Something went wrong!",
+	"error.contact": "Something went wrong 💦\nPlease check the contact 💦💦",
+
+	"command.unreadable": "Couldn't read that command!\n%s",
+	"command.suggest":    "There's no command \"%s\"\nDid you mean: %s",
+	"command.invalid":    "That command isn't written correctly!\n%s\n\n[Usage]\n%s",
+	"command.forbidden":  "Only the %s can use this command!",
+
+	"role.groupadmin": "inviter and sub-admin",
+	"role.inviter":    "inviter",
+	"role.admin":      "bot admins",
+
+	"parse.error":           "at character %d: %s",
+	"parse.error.prefix":    "prefix not found",
+	"parse.error.name":      "command name is empty",
+	"parse.error.empty":     "an argument is empty",
+	"parse.error.quote":     "quotes are only allowed at the start of an argument",
+	"parse.error.unclosed":  "quote is not closed",
+	"parse.error.separator": "a separator is required after a quote",
+
+	"arg.error.count":  "wrong number of arguments",
+	"arg.error.switch": "\"%s\" must be on or off",
+	"arg.error.int":    "\"%s\" must be a number",
+
+	"arg.switch":   "on/off",
+	"arg.minutes":  "minutes",
+	"arg.setting":  "setting",
+	"arg.range":    "time",
+	"arg.number":   "number",
+	"arg.preset":   "standard/strict/open",
+	"arg.language": "日本語/English",
+
+	"arg.bulk":            "on/off/setting=on…",
+	"arg.prefixfamily":    "normal/setting",
+	"arg.prefix":          "prefix",
+	"arg.ticketkind":      "7d/30d/permanent/trial",
+	"arg.count":           "count",
+	"arg.days":            "days",
+	"arg.broadcastfilter": "target",
+	"arg.text":            "text",
+	"arg.job":             "job",
+	"arg.plan":            "basic/pro/premium",
+
+	"help.header":         "This is the mode select! Everything starts from here!\n\nline://app/1559882908-RgxMO3P1\n\n[Commands]",
+	"help.help":           "Show the commands you can use",
+	"help.status":         "Show the bot's status",
+	"help.permission":     "Check when your permission expires",
+	"help.speed":          "Measure the sending speed",
+	"help.kickers":        "Bring back missing kickers",
+	"help.leave":          "Make all bots leave",
+	"help.kick":           "Kick the mentioned or replied member",
+	"help.ban":            "Kick the member and keep them from joining again",
+	"help.unban":          "Lift the member's ban",
+	"help.protect":        "Re-invite the member when they get kicked",
+	"help.unprotect":      "Stop protecting the member",
+	"help.promote":        "Make the member the sub admin",
+	"help.check":          "Show the current settings",
+	"help.trusted":        "Show the trusted inviters",
+	"help.namelock":       "Revert group name changes",
+	"help.iconlock":       "Revert group icon changes",
+	"help.urllock":        "Keep the invite link closed",
+	"help.invitelock":     "Cancel invitations by non-admins",
+	"help.subadmin":       "Change the sub admin by contact",
+	"help.addtrusted":     "Add a trusted inviter by contact",
+	"help.removetrusted":  "Remove a trusted inviter by contact",
+	"help.lockdown":       "Turn on every protection and kick new members",
+	"help.unlockdown":     "End the lockdown",
+	"help.addschedule":    "Turn a protection on only at set times (e.g. 22:00-07:00)",
+	"help.removeschedule": "Remove a schedule",
+	"help.preset":         "Set the protections all at once",
+	"help.language":       "Change the language the bot speaks",
+	"help.prefixes":       "Show the prefixes you can use",
+	"help.addprefix":      "Add a prefix (e.g. normal:\"bot1:\")",
+	"help.removeprefix":   "Remove an added prefix",
+	"help.replaceprefix":  "When on, only the added prefixes are used",
+	"help.bulk":           "Turn protections on or off at once (e.g. name=on icon=off)",
+	"help.templates":      "Show the groups you can copy settings from",
+	"help.copy":           "Copy settings and roles from another group",
+	"help.groups":         "Show the groups you manage",
+	"help.select":         "Choose the group to manage",
+	"help.deselect":       "Stop managing the chosen group",
+	"help.issuetickets":   "Issue tickets (e.g. 30d:10)",
+	"help.listtickets":    "Show issued tickets",
+	"help.grant":          "Grant permission to a contact (e.g. 30, permanent)",
+	"help.extend":         "Extend a contact's permission",
+	"help.revoke":         "Revoke a contact's permission",
+	"help.lookup":         "Show a contact's permission and groups",
+	"help.allgroups":      "Show every protected group",
+	"help.fleet":          "Show the status of the bot accounts",
+	"help.addadmin":       "Make a contact an admin",
+	"help.removeadmin":    "Remove a contact from the admins",
+	"help.admins":         "Show the admins",
+	"help.broadcast":      "Send an announcement to protected groups (e.g. all, expiry=7, invite=on)",
+	"help.jobs":           "Show the status of periodic jobs",
+	"help.runjob":         "Run a periodic job now",
+	"help.jobhistory":     "Show the run history of a periodic job",
+	"help.cleanup":        "Review and run the cleanup of expired groups",
+	"help.setplan":        "Change the plan of the contact",
+
+	"speed.measuring": "Measuring",
+	"speed.result":    "%fs",
+
+	"setting.changed": "%s is now %s!",
+	"setting.already": "%s is already %s!",
+	"setting.failed":  "Failed to change the settings, so nothing was changed!",
+	"setting.members": "\nInviter -> %s\nSub admin -> %s",
+
+	"bulk.empty":   "Specify the settings to change!\n\n[Examples]\non\nname=on icon=off",
+	"bulk.invalid": "Couldn't read \"%s\"!\nSettings are name, icon, url and invite, and values are on or off",
+
+	"permission.valid":   "You have permission!\n\n[Expires]\n%s",
+	"permission.expired": "Looks like your permission has expired...\n\n[Expires]\n%s",
+	"permission.none":    "Looks like you don't have permission...",
+
+	"ticket.notfound":        "That ticket wasn't found!\nPlease check the code",
+	"ticket.used":            "That ticket has already been used!",
+	"ticket.expired":         "That ticket has expired!",
+	"ticket.redeemed":        "Redeemed a %s ticket!\nYour permission is now active\n\n[Expires]\n%s",
+	"ticket.trial":           "Trial tickets are only for first-time users!",
+	"ticket.kind.invalid":    "Choose the ticket kind from %s, %s, %s and %s!",
+	"ticket.count.invalid":   "The count must be between 1 and %d!",
+	"ticket.issued":          "Issued %[2]d %[1]s tickets!\n\n%[3]s",
+	"ticket.list":            "[Tickets]\nIssued %d / Redeemed %d",
+	"ticket.status.unused":   "Unused (expires %s)",
+	"ticket.status.redeemed": "Redeemed by %s (%s)",
+	"ticket.status.expired":  "Expired",
+
+	"expiry.reminder": "Your permission expires soon!\n\n[Expires]\n%s (%d days left)\n\nSend a ticket to extend it",
+	"expiry.notice":   "The inviter's permission has expired, so protections are paused!\n\n[Expired]\n%s\n\n[Leaving on]\n%s\n\nExtend with a ticket before then to resume",
+
+	"kickers.full":    "Everyone is here!",
+	"kickers.missing": "Bringing back %d!",
+
+	"input.timeout":   "Time's up, so I stopped waiting!",
+	"input.cancelled": "Cancelled!",
+	"input.cancel":    "Send \"cancel\" to abort",
+
+	"subadmin.prompt":  "Send the contact of the account to make sub admin, or mention them!",
+	"subadmin.onlyone": "Only one member can be the sub admin!",
+	"subadmin.bot":     "A bot can't be the sub admin!",
+	"subadmin.changed": "%s is now the sub admin 🐶💙✨",
+
+	"trusted.add.prompt":    "Send the contact of the account to trust, or mention them!",
+	"trusted.remove.prompt": "Send the contact of the account to stop trusting, or mention them!",
+	"trusted.already":       "%s is already a trusted inviter!",
+	"trusted.added":         "Added %s as a trusted inviter!",
+	"trusted.notfound":      "That account isn't a trusted inviter!",
+	"trusted.removed":       "Removed from the trusted inviters!",
+	"trusted.empty":         "There are no trusted inviters!",
+	"trusted.list":          "[Trusted inviters]",
+
+	"language.unknown": "That language isn't supported!\n\n[Languages]\n日本語\nEnglish",
+	"language.changed": "Language set to English!",
+
+	"prefix.family.invalid": "Specify \"%s\" or \"%s\"!",
+	"prefix.invalid":        "A prefix must be at most %d characters without spaces or quotes!",
+	"prefix.conflict":       "It overlaps with \"%s\", so it can't be used!",
+	"prefix.already":        "\"%s\" is already a prefix!",
+	"prefix.added":          "Added the prefix \"%s\"!",
+	"prefix.removed":        "Removed the prefix \"%s\"!",
+	"prefix.notfound":       "\"%s\" isn't an added prefix!",
+	"prefix.list":           "[Prefixes]\nnormal -> %s\nsetting -> %s",
+	"prefix.replace.on":     "For kinds with added prefixes, the default prefixes are no longer used!",
+	"prefix.replace.off":    "The default prefixes are used too!",
+
+	"admin.days.invalid":   "Specify the days as a number of at least 1, or \"%s\"!",
+	"admin.extend.invalid": "Specify the days as a number of at least 1!",
+	"admin.grant.prompt":   "Send the contact to grant permission to!",
+	"admin.extend.prompt":  "Send the contact whose permission to extend!",
+	"admin.revoke.prompt":  "Send the contact whose permission to revoke!",
+	"admin.lookup.prompt":  "Send the contact to look up!",
+	"admin.revoked":        "Revoked %s's permission!",
+	"admin.user":           "[User]\n%s\n%s",
+	"admin.user.groups":    "[Invited groups] %d",
+	"admin.user.trial":     "[Trial] Used",
+	"admin.groups":         "[Protected groups] %d",
+	"admin.group":          "%s\n  inviter -> %s (expires %s)",
+	"admin.fleet":          "[Bot accounts]",
+	"admin.fleet.up":       "%d. %s -> joined %d / invited %d",
+	"admin.fleet.down":     "%d. %s -> not responding",
+	"admin.add.prompt":     "Send the contact to make an admin!",
+	"admin.remove.prompt":  "Send the contact to remove from the admins!",
+	"admin.already":        "%s is already an admin!",
+	"admin.added":          "%s is now an admin!",
+	"admin.notfound":       "That account isn't an admin!",
+	"admin.removed":        "Removed %s from the admins!",
+	"admin.list":           "[Admins]",
+
+	"broadcast.filter.invalid": "Couldn't read \"%s\"!\nTargets are all, expiry=days or setting=on/off",
+	"broadcast.empty":          "There are no target groups!",
+	"broadcast.preview":        "Sending to %d groups\n\n%s",
+	"broadcast.confirm":        "Send \"yes\" to send!",
+	"broadcast.started":        "Started sending to %d groups with %d accounts!",
+	"broadcast.report":         "Sending finished!\n\n[Delivered]\n%d\n\n[Failed]\n%d\n\n[Time]\n%s",
+	"broadcast.more":           "and %d more",
+
+	"feature.name":     "Group name lock",
+	"feature.image":    "Icon lock",
+	"feature.url":      "Invite link block",
+	"feature.invite":   "Invite block",
+	"feature.lockdown": "Lockdown",
+	"feature.ban":      "Ban",
+	"feature.schedule": "Schedules",
+
+	"plan.summary":   "[Plan]\n%s\n\n[Protected groups]\n%s\n\n[Features]\n%s",
+	"plan.unlimited": "Unlimited",
+	"plan.groups":    "Up to %d",
+	"plan.feature":   "%s isn't available on the %s plan!\n\n[Available on]\n%s",
+	"plan.quota":     "The %s plan protects up to %d groups, so the invitation was declined!\nRemove the bot from another group or change your plan\n\n[Larger plans]\n%s",
+	"plan.invalid":   "Choose a plan from %s, %s or %s!",
+	"plan.prompt":    "Send the contact whose plan to change!",
+	"plan.changed":   "Changed the plan of %s!\n\n%s",
+	"plan.trial":     "Trial",
+
+	"trial.started": "Nice to meet you! Your %d-day free trial has started!\n\n[Expires]\n%s\n\n%s\n\n[Upgrade]\nSend a ticket code (%s...) in this chat to lift the trial limits right away",
+
+	"invitation.rejected": "The invitation was declined because you don't have permission!\nThe free trial is once per person\n\n[Getting a ticket]\nContact the author\nhttp://line.me/ti/p/%%40djv5227g\n\n[How to use]\nSend the ticket code (%s...) to the bot in a private chat, then invite it again",
+
+	"cleanup.empty":       "There is nothing to clean up!",
+	"cleanup.groups":      "[Groups to leave] %d",
+	"cleanup.owners":      "[Affected inviters] %d",
+	"cleanup.invitations": "[Invitations to reject] %d",
+	"cleanup.invitation":  "Account %d -> %s",
+	"cleanup.confirm":     "Send \"yes\" to run the cleanup!",
+	"cleanup.started":     "Cleanup started!",
+	"cleanup.finished":    "Cleanup finished!\n\n[Left]\n%d\n\n[Rejected]\n%d\n\n[Time]\n%s",
+
+	"job.list":          "[Jobs]",
+	"job.running":       "Running",
+	"job.next":          "Next: %s",
+	"job.run":           "%s (%s) %s%s",
+	"job.result.ok":     "OK",
+	"job.result.error":  "Failed: %s",
+	"job.manual":        " [manual]",
+	"job.triggered":     "Started %s!",
+	"job.notfound":      "There is no job named %s!",
+	"job.busy":          "%s is already running!",
+	"job.history":       "[History of %s]",
+	"job.history.empty": "%s hasn't run yet!",
+
+	"lockdown.range":     "Lockdown time must be between 1 and %d minutes!",
+	"lockdown.already":   "Already in lockdown!\n\n[Ends at]\n%s",
+	"lockdown.started":   "Lockdown started!\nEvery protection is on and the invite link is closed\n\n[Invitations cancelled]\n%d\n\n[Ends at]\n%s",
+	"lockdown.notactive": "Not in lockdown!",
+	"lockdown.ended":     "Lockdown ended!\n\n[Invitations cancelled]\n%d\n\n[Members kicked]\n%d",
+	"lockdown.restored":  "Protections that were off before the lockdown have been restored",
+
+	"schedule.unknown":  "That setting can't be scheduled!",
+	"schedule.invalid":  "The time isn't valid!\n\n[Examples]\n22:00-07:00\n2026/10/20-2026/10/25",
+	"schedule.added":    "Schedule added!\n\n%s",
+	"schedule.id":       "Specify the schedule number!",
+	"schedule.notfound": "That schedule wasn't found!",
+	"schedule.removed":  "Schedule removed!\n\n%s",
+	"schedule.list":     "[Schedules]",
+	"schedule.applied":  "Scheduled: %s",
+
+	"preset.unknown": "That preset doesn't exist!\n\n[Presets]\n%s\n%s\n%s",
+	"preset.applied": "Applied the \"%s\" preset!\n\n%s",
+
+	"template.empty":   "There are no groups to copy from!",
+	"template.list":    "[Groups to copy from]",
+	"template.hint":    "Send \"%s:number\" to copy!",
+	"template.invalid": "That number isn't valid!\nCheck with \"%s\"!",
+
+	"copy.failed": "Failed to copy roles, bans and schedules!\n\n%s",
+	"copy.done":   "Copied settings, sub admin, trusted inviters, bans, protected members and schedules!\n\n%s",
+
+	"remote.none":        "You don't manage any groups!",
+	"remote.list":        "[Your groups]",
+	"remote.hint":        "Send \"%s:number\" to choose one!",
+	"remote.invalid":     "That number isn't valid!\nCheck with \"%s\"!",
+	"remote.selected":    "Chose \"%s\"!\n%s and %s commands now apply to this group\nSend \"%s\" to stop",
+	"remote.deselected":  "Stopped managing the group!",
+	"remote.notselected": "Choose a group with \"%s\" first!",
+	"remote.forbidden":   "You can no longer manage that group, so it was deselected!",
+	"remote.prompt":      "Send the contact of the target account!",
+
+	"moderation.notarget":        "Mention the target or reply to their message!",
+	"moderation.skipped":         "%s skipped because they are admins or bots",
+	"moderation.nothing":         "Nothing was done!",
+	"moderation.kicked":          "Kicked %s!",
+	"moderation.banned":          "Banned %s!",
+	"moderation.unbanned":        "Unbanned %s!",
+	"moderation.unban.empty":     "None of them are banned!",
+	"moderation.protected":       "Protecting %s!\nThey will be re-invited if kicked",
+	"moderation.unprotected":     "Stopped protecting %s!",
+	"moderation.unprotect.empty": "None of them are protected!",
+
+	"wizard.start":              "Inviter, let's do the initial setup!\nSend \"skip\" to skip a question, or \"cancel\" to stop the setup",
+	"wizard.language":           "[1/3] Choose a language!\n1. 日本語\n2. English",
+	"wizard.language.invalid":   "Answer with 1 or 2!",
+	"wizard.protection":         "[2/3] Choose the protection level!\n1. %s (all on except invite block)\n2. %s (all on)\n3. %s (all off)",
+	"wizard.protection.invalid": "Answer with 1 to 3!",
+	"wizard.subadmin":           "[3/3] Send the contact of the account to make sub admin, or mention them!\nSend \"none\" if there is nobody",
+	"wizard.subadmin.invalid":   "Send a contact or \"none\"!",
+	"wizard.timeout":            "No reply, so the setup is over!\nThe remaining items keep their defaults\nYou can change them later with \"setting:\" commands!",
+	"wizard.cancelled":          "Setup cancelled!",
+	"wizard.done":               "Setup complete!\nCheck anytime with \"%s\"",
+}
@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	LANG_JA = "ja"
+	LANG_EN = "en"
+
+	DEFAULT_LANGUAGE = LANG_JA
+)
+
+var catalogs = map[string]map[string]string{
+	LANG_JA: ja,
+	LANG_EN: en,
+}
+
+var languageNames = map[string]string{
+	"ja":       LANG_JA,
+	"日本語":      LANG_JA,
+	"japanese": LANG_JA,
+	"en":       LANG_EN,
+	"english":  LANG_EN,
+	"英語":       LANG_EN,
+}
+
+func IsSupported(language string) bool {
+	_, ok := catalogs[language]
+	return ok
+}
+
+func Resolve(name string) (string, bool) {
+	language, ok := languageNames[strings.ToLower(strings.TrimSpace(name))]
+	return language, ok
+}
+
+func T(language string, key string, args ...interface{}) string {
+	format, ok := catalogs[language][key]
+	if !ok {
+		format, ok = catalogs[DEFAULT_LANGUAGE][key]
+		if !ok {
+			format = key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
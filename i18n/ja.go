@@ -0,0 +1,325 @@
+package i18n
+
+var ja = map[string]string{
+	"greeting": "認証完了なのです！\n" +
+		"さあ、張り切って参りましょうかっ\n\n" +
+		"※台詞はbeatmania IIDX 26 Rootageのシステムボイスを参考にしています。\n\n" +
+		"[作者]\n" +
+		"のえる\n" +
+		"http://line.me/ti/p/%40djv5227g\n\n" +
+		"※本BOTは非公式です。",
+
+	"prefix.normal":  "たまき:",
+	"prefix.setting": "設定:",
+
+	"common.none":      "なし",
+	"common.separator": "、",
+	"account.deleted":  "アカウント削除",
+	"group.unknown":    "不明なグループ",
+	"switch.on":        "オン",
+	"switch.off":       "オフ",
+
+	"protection.name":   "グループ名ロック",
+	"protection.image":  "アイコンロック",
+	"protection.url":    "招待リンク拒否",
+	"protection.invite": "招待拒否",
+
+	"error.generic": "エラーが発生したのですっ",
+	"error.contact": "エラーが発生しました💦\n連絡先をお確かめください💦💦",
+
+	"command.unreadable": "コマンドを読み取れなかったのですっ\n%s",
+	"command.suggest":    "「%s」というコマンドは無いのです\nもしかして: %s",
+	"command.invalid":    "コマンドの書き方が正しくないのですっ\n%s\n\n[使い方]\n%s",
+	"command.forbidden":  "このコマンドは%sだけが使えるのですっ",
+
+	"role.groupadmin": "招待者とサブ管理者",
+	"role.inviter":    "招待者",
+	"role.admin":      "BOTの管理者",
+
+	"parse.error":           "%d文字目: %s",
+	"parse.error.prefix":    "プレフィックスが見つからないのです",
+	"parse.error.name":      "コマンド名が空なのです",
+	"parse.error.empty":     "空の引数があるのです",
+	"parse.error.quote":     "引用符は引数の先頭にだけ書けるのです",
+	"parse.error.unclosed":  "引用符が閉じられていないのです",
+	"parse.error.separator": "引用符の後には区切り文字が必要なのです",
+
+	"arg.error.count":  "引数の数が正しくないのです",
+	"arg.error.switch": "「%s」にはオンかオフを指定するのです",
+	"arg.error.int":    "「%s」には数字を指定するのです",
+
+	"arg.switch":   "オン/オフ",
+	"arg.minutes":  "分",
+	"arg.setting":  "設定",
+	"arg.range":    "時間",
+	"arg.number":   "番号",
+	"arg.preset":   "標準/厳重/開放",
+	"arg.language": "日本語/English",
+
+	"arg.bulk":            "オン/オフ/設定=オン…",
+	"arg.prefixfamily":    "通常/設定",
+	"arg.prefix":          "プレフィックス",
+	"arg.ticketkind":      "7日/30日/永久/体験",
+	"arg.count":           "枚数",
+	"arg.days":            "日数",
+	"arg.broadcastfilter": "対象",
+	"arg.text":            "本文",
+	"arg.job":             "ジョブ名",
+	"arg.plan":            "ベーシック/プロ/プレミアム",
+
+	"help.header":         "ここはモードセレクト！この場所から全てが始まるのですっ\n\nline://app/1559882908-RgxMO3P1\n\n[コマンド]",
+	"help.help":           "使えるコマンドの一覧を表示するのです",
+	"help.status":         "BOTの稼働状況を表示するのです",
+	"help.permission":     "あなたの権限の有効期限を確認するのです",
+	"help.speed":          "送信速度を計測するのです",
+	"help.kickers":        "いないキッカーを補充するのです",
+	"help.leave":          "BOTを全員退会させるのです",
+	"help.kick":           "メンションかリプライした相手をキックするのです",
+	"help.ban":            "相手をキックして、二度と参加できないようにするのです",
+	"help.unban":          "相手のバンを解除するのです",
+	"help.protect":        "相手がキックされたら招待し直すのです",
+	"help.unprotect":      "相手の保護を解除するのです",
+	"help.promote":        "相手をサブ管理者にするのです",
+	"help.check":          "現在の設定を表示するのです",
+	"help.trusted":        "信頼招待者の一覧を表示するのです",
+	"help.namelock":       "グループ名の変更を元に戻すのです",
+	"help.iconlock":       "グループアイコンの変更を元に戻すのです",
+	"help.urllock":        "招待リンクを開けられないようにするのです",
+	"help.invitelock":     "管理者以外の招待をキャンセルするのです",
+	"help.subadmin":       "連絡先でサブ管理者を変更するのです",
+	"help.addtrusted":     "連絡先で信頼招待者を追加するのです",
+	"help.removetrusted":  "連絡先で信頼招待者を削除するのです",
+	"help.lockdown":       "全ての保護をオンにして、参加者をキックするのです",
+	"help.unlockdown":     "ロックダウンを終了するのです",
+	"help.addschedule":    "決まった時間だけ保護をオンにするのです (例: 22:00-07:00)",
+	"help.removeschedule": "スケジュールを削除するのです",
+	"help.preset":         "保護をまとめて設定するのです",
+	"help.language":       "BOTが話す言語を変更するのです",
+	"help.prefixes":       "使えるプレフィックスを表示するのです",
+	"help.addprefix":      "プレフィックスを追加するのです (例: 通常:\"bot1:\")",
+	"help.removeprefix":   "追加したプレフィックスを削除するのです",
+	"help.replaceprefix":  "オンにすると、追加したプレフィックスだけを使うのです",
+	"help.bulk":           "保護をまとめてオンオフするのです (例: name=オン icon=オフ)",
+	"help.templates":      "設定をコピーできるグループを表示するのです",
+	"help.copy":           "他のグループの設定と権限をコピーするのです",
+	"help.groups":         "管理しているグループの一覧を表示するのです",
+	"help.select":         "操作するグループを選ぶのです",
+	"help.deselect":       "グループの選択をやめるのです",
+	"help.issuetickets":   "チケットを発行するのです (例: 30日:10)",
+	"help.listtickets":    "発行したチケットを表示するのです",
+	"help.grant":          "連絡先の相手に権限を付与するのです (例: 30、永久)",
+	"help.extend":         "連絡先の相手の権限を延長するのです",
+	"help.revoke":         "連絡先の相手の権限を取り消すのです",
+	"help.lookup":         "連絡先の相手の権限とグループを表示するのです",
+	"help.allgroups":      "保護している全てのグループを表示するのです",
+	"help.fleet":          "BOTアカウントの稼働状況を表示するのです",
+	"help.addadmin":       "連絡先の相手を管理者にするのです",
+	"help.removeadmin":    "連絡先の相手を管理者から外すのです",
+	"help.admins":         "管理者の一覧を表示するのです",
+	"help.broadcast":      "保護グループにお知らせを送るのです (例: 全て、期限=7、invite=オン)",
+	"help.jobs":           "定期ジョブの状態を表示するのです",
+	"help.runjob":         "定期ジョブを今すぐ実行するのです",
+	"help.jobhistory":     "定期ジョブの実行履歴を表示するのです",
+	"help.cleanup":        "期限切れグループの掃除内容を確認して実行するのです",
+	"help.setplan":        "連絡先の相手のプランを変更するのです",
+
+	"speed.measuring": "計測中",
+	"speed.result":    "%f秒",
+
+	"setting.changed": "%sを%sにしたのですっ",
+	"setting.already": "%sは既に%sなのですっ",
+	"setting.failed":  "設定の変更に失敗したので、何も変えていないのですっ",
+	"setting.members": "\n招待者 -> %s\nサブ管理者 -> %s",
+
+	"bulk.empty":   "変更する設定を指定するのですっ\n\n[例]\nオン\nname=オン icon=オフ",
+	"bulk.invalid": "「%s」が読み取れなかったのですっ\n設定はname、icon、url、inviteで、値はオンかオフなのです",
+
+	"permission.valid":   "あなたは権限を所持してるのですっ\n\n[有効期限]\n%s",
+	"permission.expired": "あなたの権限は既に失効されているみたいです。。。\n\n[有効期限]\n%s",
+	"permission.none":    "あなたは権限を所持していないみたいです。。。",
+
+	"ticket.notfound":        "そのチケットは見つからないのですっ\nコードをお確かめください",
+	"ticket.used":            "そのチケットは既に使われているのですっ",
+	"ticket.expired":         "そのチケットは有効期限が切れているのですっ",
+	"ticket.redeemed":        "%sのチケットを使ったのですっ\n権限が有効になったのです\n\n[有効期限]\n%s",
+	"ticket.trial":           "体験チケットは初めての方だけが使えるのですっ",
+	"ticket.kind.invalid":    "チケットの種類は%s、%s、%s、%sから選ぶのですっ",
+	"ticket.count.invalid":   "枚数は1〜%d枚で指定するのですっ",
+	"ticket.issued":          "%sのチケットを%d枚発行したのですっ\n\n%s",
+	"ticket.list":            "[チケット]\n発行 %d枚 / 使用済み %d枚",
+	"ticket.status.unused":   "未使用 (有効期限 %s)",
+	"ticket.status.redeemed": "%sが使用 (%s)",
+	"ticket.status.expired":  "期限切れ",
+
+	"expiry.reminder": "権限の有効期限が近づいているのですっ\n\n[有効期限]\n%s (残り%d日)\n\nチケットを送ると延長できるのです",
+	"expiry.notice":   "招待者さんの権限が切れたので、保護を止めているのですっ\n\n[有効期限]\n%s\n\n[退会予定]\n%s\n\nそれまでにチケットで延長すると元に戻るのです",
+
+	"kickers.full":    "全員いるのですっ",
+	"kickers.missing": "%d体補充するのですっ",
+
+	"input.timeout":   "時間切れなので受付を終了したのですっ",
+	"input.cancelled": "キャンセルしたのですっ",
+	"input.cancel":    "「キャンセル」で取り消せるのです",
+
+	"subadmin.prompt":  "サブ管理者にしたいアカウントの連絡先を送信するか、メンションするのですっ",
+	"subadmin.onlyone": "サブ管理者にできるのは1人だけなのですっ",
+	"subadmin.bot":     "BOTはサブ管理者にできないのですっ",
+	"subadmin.changed": "%sをサブ管理者に設定しました🐶💙✨",
+
+	"trusted.add.prompt":    "信頼招待者に追加したいアカウントの連絡先を送信するか、メンションするのですっ",
+	"trusted.remove.prompt": "信頼招待者から削除したいアカウントの連絡先を送信するか、メンションするのですっ",
+	"trusted.already":       "%sは既に信頼招待者なのですっ",
+	"trusted.added":         "%sを信頼招待者に追加したのですっ",
+	"trusted.notfound":      "そのアカウントは信頼招待者ではないのですっ",
+	"trusted.removed":       "信頼招待者から削除したのですっ",
+	"trusted.empty":         "信頼招待者はいないのですっ",
+	"trusted.list":          "[信頼招待者]",
+
+	"language.unknown": "その言語には対応していないのですっ\n\n[言語]\n日本語\nEnglish",
+	"language.changed": "言語を日本語にしたのですっ",
+
+	"prefix.family.invalid": "「%s」か「%s」を指定するのですっ",
+	"prefix.invalid":        "プレフィックスは空白や引用符を含まない%d文字以内で指定するのですっ",
+	"prefix.conflict":       "「%s」と重なるので使えないのですっ",
+	"prefix.already":        "「%s」は既にプレフィックスなのですっ",
+	"prefix.added":          "プレフィックス「%s」を追加したのですっ",
+	"prefix.removed":        "プレフィックス「%s」を削除したのですっ",
+	"prefix.notfound":       "「%s」は追加されたプレフィックスではないのですっ",
+	"prefix.list":           "[プレフィックス]\n通常 -> %s\n設定 -> %s",
+	"prefix.replace.on":     "プレフィックスを追加した種類では、標準のプレフィックスを使わないのですっ",
+	"prefix.replace.off":    "標準のプレフィックスも使うのですっ",
+
+	"admin.days.invalid":   "日数は1以上の数字か「%s」で指定するのですっ",
+	"admin.extend.invalid": "日数は1以上の数字で指定するのですっ",
+	"admin.grant.prompt":   "権限を付与する相手の連絡先を送信するのですっ",
+	"admin.extend.prompt":  "権限を延長する相手の連絡先を送信するのですっ",
+	"admin.revoke.prompt":  "権限を取り消す相手の連絡先を送信するのですっ",
+	"admin.lookup.prompt":  "確認する相手の連絡先を送信するのですっ",
+	"admin.revoked":        "%sの権限を取り消したのですっ",
+	"admin.user":           "[ユーザー]\n%s\n%s",
+	"admin.user.groups":    "[招待したグループ] %d件",
+	"admin.user.trial":     "[体験] 利用済み",
+	"admin.groups":         "[保護グループ] %d件",
+	"admin.group":          "%s\n  招待者 -> %s (有効期限 %s)",
+	"admin.fleet":          "[BOTアカウント]",
+	"admin.fleet.up":       "%d. %s -> 参加 %d / 招待 %d",
+	"admin.fleet.down":     "%d. %s -> 応答なし",
+	"admin.add.prompt":     "管理者にする相手の連絡先を送信するのですっ",
+	"admin.remove.prompt":  "管理者から外す相手の連絡先を送信するのですっ",
+	"admin.already":        "%sは既に管理者なのですっ",
+	"admin.added":          "%sを管理者にしたのですっ",
+	"admin.notfound":       "その相手は管理者ではないのですっ",
+	"admin.removed":        "%sを管理者から外したのですっ",
+	"admin.list":           "[管理者]",
+
+	"broadcast.filter.invalid": "「%s」が読み取れなかったのですっ\n対象は全て、期限=日数、設定=オン/オフで指定するのです",
+	"broadcast.empty":          "対象のグループが無いのですっ",
+	"broadcast.preview":        "%d件のグループに送信するのです\n\n%s",
+	"broadcast.confirm":        "「はい」で送信するのですっ",
+	"broadcast.started":        "%d件のグループに%d体で送信を始めたのですっ",
+	"broadcast.report":         "送信が終わったのですっ\n\n[成功]\n%d件\n\n[失敗]\n%d件\n\n[所要時間]\n%s",
+	"broadcast.more":           "他%d件",
+
+	"feature.name":     "グループ名ロック",
+	"feature.image":    "アイコンロック",
+	"feature.url":      "招待リンク拒否",
+	"feature.invite":   "招待拒否",
+	"feature.lockdown": "ロックダウン",
+	"feature.ban":      "バン",
+	"feature.schedule": "スケジュール",
+
+	"plan.summary":   "[プラン]\n%s\n\n[保護できるグループ]\n%s\n\n[使える機能]\n%s",
+	"plan.unlimited": "無制限",
+	"plan.groups":    "%d件まで",
+	"plan.feature":   "%sは%sプランでは使えないのですっ\n\n[使えるプラン]\n%s",
+	"plan.quota":     "%sプランで保護できるグループは%d件までなので、招待をお断りしたのですっ\n他のグループからBOTを退会させるか、プランを変更するのです\n\n[上位プラン]\n%s",
+	"plan.invalid":   "プランは%s、%s、%sから選ぶのですっ",
+	"plan.prompt":    "プランを変更する相手の連絡先を送信するのですっ",
+	"plan.changed":   "%sのプランを変更したのですっ\n\n%s",
+	"plan.trial":     "体験",
+
+	"trial.started": "はじめまして！%d日間の無料体験で保護を始めたのですっ\n\n[有効期限]\n%s\n\n%s\n\n[アップグレード]\nチケットコード (%s〜) をこのトークに送ると、体験の制限がすぐに外れるのです",
+
+	"invitation.rejected": "権限が無いので招待をお断りしたのですっ\n無料体験は1人1回までなのです\n\n[チケットの入手]\n作者に連絡するのです\nhttp://line.me/ti/p/%%40djv5227g\n\n[使い方]\nチケットコード (%s〜) をBOTとの個人トークに送ってから、もう一度招待するのです",
+
+	"cleanup.empty":       "掃除するものは無いのですっ",
+	"cleanup.groups":      "[退出するグループ] %d件",
+	"cleanup.owners":      "[影響を受ける招待者] %d人",
+	"cleanup.invitations": "[拒否する招待] %d件",
+	"cleanup.invitation":  "%d体目 -> %s",
+	"cleanup.confirm":     "「はい」で掃除を実行するのですっ",
+	"cleanup.started":     "掃除を始めたのですっ",
+	"cleanup.finished":    "掃除が終わったのですっ\n\n[退出]\n%d件\n\n[招待拒否]\n%d件\n\n[所要時間]\n%s",
+
+	"job.list":          "[ジョブ]",
+	"job.running":       "実行中なのです",
+	"job.next":          "次回: %s",
+	"job.run":           "%s (%s) %s%s",
+	"job.result.ok":     "成功",
+	"job.result.error":  "失敗: %s",
+	"job.manual":        " [手動]",
+	"job.triggered":     "%sを実行したのですっ",
+	"job.notfound":      "%sというジョブは無いのですっ",
+	"job.busy":          "%sは実行中なのですっ",
+	"job.history":       "[%sの履歴]",
+	"job.history.empty": "%sはまだ実行されていないのですっ",
+
+	"lockdown.range":     "ロックダウンの時間は1〜%d分で指定するのですっ",
+	"lockdown.already":   "既にロックダウン中なのですっ\n\n[終了予定]\n%s",
+	"lockdown.started":   "ロックダウンを開始したのですっ\n全ての保護をオンにして、招待リンクを閉じたのです\n\n[招待キャンセル]\n%d件\n\n[終了予定]\n%s",
+	"lockdown.notactive": "ロックダウン中ではないのですっ",
+	"lockdown.ended":     "ロックダウンを終了したのですっ\n\n[招待キャンセル]\n%d件\n\n[参加者キック]\n%d人",
+	"lockdown.restored":  "ロックダウン前にオフだった保護は元に戻したのです",
+
+	"schedule.unknown":  "その設定はスケジュールできないのですっ",
+	"schedule.invalid":  "時間の指定が正しくないのですっ\n\n[例]\n22:00-07:00\n2026/10/20-2026/10/25",
+	"schedule.added":    "スケジュールを追加したのですっ\n\n%s",
+	"schedule.id":       "スケジュールの番号を指定するのですっ",
+	"schedule.notfound": "そのスケジュールは見つからないのですっ",
+	"schedule.removed":  "スケジュールを削除したのですっ\n\n%s",
+	"schedule.list":     "[スケジュール]",
+	"schedule.applied":  "スケジュールにより%s",
+
+	"preset.unknown": "そのプリセットは無いのですっ\n\n[プリセット]\n%s\n%s\n%s",
+	"preset.applied": "プリセット「%s」を適用したのですっ\n\n%s",
+
+	"template.empty":   "コピーできるグループが無いのですっ",
+	"template.list":    "[コピー元グループ]",
+	"template.hint":    "「%s:番号」でコピーするのですっ",
+	"template.invalid": "番号が正しくないのですっ\n「%s」で確認するのですっ",
+
+	"copy.failed": "権限、バンリスト、スケジュールのコピーに失敗したのですっ\n\n%s",
+	"copy.done":   "設定、サブ管理者、信頼招待者、バンリスト、保護メンバー、スケジュールをコピーしたのですっ\n\n%s",
+
+	"remote.none":        "管理しているグループが無いのですっ",
+	"remote.list":        "[管理グループ]",
+	"remote.hint":        "「%s:番号」で選ぶのですっ",
+	"remote.invalid":     "番号が正しくないのですっ\n「%s」で確認するのですっ",
+	"remote.selected":    "「%s」を選んだのですっ\n%sと%sのコマンドはこのグループに使われるのです\n「%s」で選択をやめられるのです",
+	"remote.deselected":  "グループの選択をやめたのですっ",
+	"remote.notselected": "先に「%s」でグループを選ぶのですっ",
+	"remote.forbidden":   "そのグループを管理する権限が無くなったので、選択をやめたのですっ",
+	"remote.prompt":      "対象のアカウントの連絡先を送信するのですっ",
+
+	"moderation.notarget":        "対象をメンションするか、対象のメッセージにリプライするのですっ",
+	"moderation.skipped":         "%sは管理者かBOTなので対象外なのです",
+	"moderation.nothing":         "何もできなかったのですっ",
+	"moderation.kicked":          "%sをキックしたのですっ",
+	"moderation.banned":          "%sをバンしたのですっ",
+	"moderation.unbanned":        "%sのバンを解除したのですっ",
+	"moderation.unban.empty":     "バンされているアカウントはいないのですっ",
+	"moderation.protected":       "%sを保護したのですっ\nキックされたら招待し直すのです",
+	"moderation.unprotected":     "%sの保護を解除したのですっ",
+	"moderation.unprotect.empty": "保護されているアカウントはいないのですっ",
+
+	"wizard.start":              "招待者さん、初期設定をするのですっ\n「スキップ」で質問を飛ばして、「キャンセル」で初期設定をやめられるのです",
+	"wizard.language":           "[1/3] 使う言語を選ぶのですっ\n1. 日本語\n2. English",
+	"wizard.language.invalid":   "1か2で答えるのですっ",
+	"wizard.protection":         "[2/3] 保護の強さを選ぶのですっ\n1. %s (招待拒否以外をオン)\n2. %s (全てオン)\n3. %s (全てオフ)",
+	"wizard.protection.invalid": "1〜3で答えるのですっ",
+	"wizard.subadmin":           "[3/3] サブ管理者にしたいアカウントの連絡先を送信するか、メンションするのですっ\nいなければ「なし」と送信するのです",
+	"wizard.subadmin.invalid":   "連絡先か「なし」を送信するのですっ",
+	"wizard.timeout":            "返事が無いので初期設定を終了するのですっ\n残りの項目は初期設定のままなのです\n後から「設定:」コマンドで変更できるのですっ",
+	"wizard.cancelled":          "初期設定を中止したのですっ",
+	"wizard.done":               "初期設定が完了したのですっ\n「%s」でいつでも確認できるのです",
+}
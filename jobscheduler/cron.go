@@ -0,0 +1,107 @@
+package jobscheduler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const MAX_SEARCH_MINUTES = 366 * 24 * 60
+
+type field struct {
+	values     map[int]bool
+	restricted bool
+}
+
+type Schedule struct {
+	spec    string
+	minute  field
+	hour    field
+	day     field
+	month   field
+	weekday field
+}
+
+var fieldRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+func Parse(spec string) (*Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return nil, errors.New("Schedule must have 5 fields.")
+	}
+	fields := [5]field{}
+	for i, part := range parts {
+		f, err := parseField(part, fieldRanges[i][0], fieldRanges[i][1])
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = f
+	}
+	return &Schedule{spec, fields[0], fields[1], fields[2], fields[3], fields[4]}, nil
+}
+
+func parseField(text string, min int, max int) (field, error) {
+	f := field{values: map[int]bool{}, restricted: text != "*"}
+	for _, item := range strings.Split(text, ",") {
+		step := 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s <= 0 {
+				return f, errors.New("Schedule step is wrong.")
+			}
+			step = s
+			item = item[:i]
+		}
+		start, end := min, max
+		if item != "*" {
+			bounds := strings.SplitN(item, "-", 2)
+			s, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return f, errors.New("Schedule value is wrong.")
+			}
+			start, end = s, s
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return f, errors.New("Schedule value is wrong.")
+				}
+			} else if step > 1 {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return f, errors.New("Schedule value is out of range.")
+		}
+		for v := start; v <= end; v += step {
+			f.values[v] = true
+		}
+	}
+	return f, nil
+}
+
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+func (s *Schedule) Match(t time.Time) bool {
+	if !s.minute.values[t.Minute()] || !s.hour.values[t.Hour()] || !s.month.values[int(t.Month())] {
+		return false
+	}
+	day := s.day.values[t.Day()]
+	weekday := s.weekday.values[int(t.Weekday())]
+	if s.day.restricted && s.weekday.restricted {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+func (s *Schedule) Next(after time.Time) (time.Time, bool) {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < MAX_SEARCH_MINUTES; i++ {
+		if s.Match(t) {
+			return t, true
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}, false
+}
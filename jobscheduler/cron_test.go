@@ -0,0 +1,152 @@
+package jobscheduler
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func fieldValues(f field) []int {
+	values := []int{}
+	for v := range f.values {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return values
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"empty", ""},
+		{"too few fields", "* * * *"},
+		{"too many fields", "* * * * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "* 24 * * *"},
+		{"day below range", "* * 0 * *"},
+		{"month out of range", "* * * 13 *"},
+		{"weekday out of range", "* * * * 7"},
+		{"negative value", "-1 * * * *"},
+		{"not a number", "a * * * *"},
+		{"reversed range", "* 5-3 * * *"},
+		{"range end not a number", "* 1-x * * *"},
+		{"range end out of range", "* 1-24 * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"negative step", "*/-1 * * * *"},
+		{"step not a number", "*/x * * * *"},
+		{"empty list item", "1,,2 * * * *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if s, err := Parse(tt.spec); err == nil {
+				t.Errorf("Parse(%q) = %v, want error", tt.spec, s)
+			}
+		})
+	}
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		min, max   int
+		values     []int
+		restricted bool
+	}{
+		{"wildcard", "*", 0, 6, []int{0, 1, 2, 3, 4, 5, 6}, false},
+		{"single value", "5", 0, 59, []int{5}, true},
+		{"list", "1,15,30", 0, 59, []int{1, 15, 30}, true},
+		{"range", "9-12", 0, 23, []int{9, 10, 11, 12}, true},
+		{"range bounds", "0-59", 0, 59, fieldRangeValues(0, 59, 1), true},
+		{"wildcard step", "*/15", 0, 59, []int{0, 15, 30, 45}, true},
+		{"wildcard step from one", "*/10", 1, 31, []int{1, 11, 21, 31}, true},
+		{"range step", "1-10/3", 0, 59, []int{1, 4, 7, 10}, true},
+		{"value step runs to max", "5/20", 0, 59, []int{5, 25, 45}, true},
+		{"step larger than range", "*/40", 0, 23, []int{0}, true},
+		{"overlapping items", "1-3,2-4", 0, 59, []int{1, 2, 3, 4}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseField(tt.text, tt.min, tt.max)
+			if err != nil {
+				t.Fatalf("parseField(%q) returned error: %v", tt.text, err)
+			}
+			if values := fieldValues(f); !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("values = %v, want %v", values, tt.values)
+			}
+			if f.restricted != tt.restricted {
+				t.Errorf("restricted = %v, want %v", f.restricted, tt.restricted)
+			}
+		})
+	}
+}
+
+func fieldRangeValues(start int, end int, step int) []int {
+	values := []int{}
+	for v := start; v <= end; v += step {
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestNext(t *testing.T) {
+	JST, _ := time.LoadLocation("Asia/Tokyo")
+	at := func(text string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04:05", text, JST)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	tests := []struct {
+		name  string
+		spec  string
+		after string
+		next  string
+	}{
+		{"every minute", "* * * * *", "2026-10-19 10:07:30", "2026-10-19 10:08:00"},
+		{"strictly after", "7 10 * * *", "2026-10-19 10:07:00", "2026-10-20 10:07:00"},
+		{"every fifteen minutes", "*/15 * * * *", "2026-10-19 10:07:00", "2026-10-19 10:15:00"},
+		{"next hour", "0 * * * *", "2026-10-19 10:59:59", "2026-10-19 11:00:00"},
+		{"next day", "0 4 * * *", "2026-10-19 04:00:00", "2026-10-20 04:00:00"},
+		{"month boundary", "0 0 1 * *", "2026-10-19 12:00:00", "2026-11-01 00:00:00"},
+		{"thirty-first skips short months", "0 0 31 * *", "2026-10-31 00:00:00", "2026-12-31 00:00:00"},
+		{"year boundary", "0 0 1 1 *", "2026-12-31 23:59:00", "2027-01-01 00:00:00"},
+		{"last minute of year", "59 23 31 12 *", "2026-01-01 00:00:00", "2026-12-31 23:59:00"},
+		{"weekday only", "0 9 * * 1", "2026-10-19 09:00:00", "2026-10-26 09:00:00"},
+		{"weekday range", "30 8 * * 1-5", "2026-10-23 09:00:00", "2026-10-26 08:30:00"},
+		{"day or weekday hits weekday", "0 12 13 * 5", "2026-10-19 00:00:00", "2026-10-23 12:00:00"},
+		{"day or weekday hits day", "0 12 13 * 5", "2026-10-12 13:00:00", "2026-10-13 12:00:00"},
+		{"day and weekday both match", "0 12 13 * 5", "2026-11-12 13:00:00", "2026-11-13 12:00:00"},
+		{"restricted day with wildcard weekday", "0 0 13 * *", "2026-10-13 00:00:00", "2026-11-13 00:00:00"},
+		{"stepped day is restricted", "0 0 */10 * 0", "2026-10-19 00:00:00", "2026-10-21 00:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.spec, err)
+			}
+			next, ok := s.Next(at(tt.after))
+			if !ok {
+				t.Fatalf("Next(%s) found no time", tt.after)
+			}
+			if want := at(tt.next); !next.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, next.Format("2006-01-02 15:04"), want.Format("2006-01-02 15:04"))
+			}
+		})
+	}
+}
+
+func TestNextNotFound(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if next, ok := s.Next(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("Next() = %s, want no time", next)
+	}
+}
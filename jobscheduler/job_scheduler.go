@@ -0,0 +1,175 @@
+package jobscheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const MAX_HISTORY = 20
+
+var (
+	ErrJobNotFound = errors.New("Job is not found.")
+	ErrJobRunning  = errors.New("Job is already running.")
+)
+
+type Run struct {
+	Start  time.Time
+	End    time.Time
+	Manual bool
+	Err    error
+}
+
+type Job struct {
+	Name     string
+	Schedule *Schedule
+	Func     func() error
+	running  bool
+	history  []Run
+}
+
+type Status struct {
+	Name     string
+	Schedule string
+	Running  bool
+	Next     time.Time
+	Last     *Run
+}
+
+type Scheduler struct {
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	location *time.Location
+}
+
+func New(location *time.Location) *Scheduler {
+	return &Scheduler{jobs: map[string]*Job{}, location: location}
+}
+
+func (s *Scheduler) Add(name string, spec string, f func() error) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; ok {
+		return errors.New("Job name is duplicated.")
+	}
+	s.jobs[name] = &Job{Name: name, Schedule: schedule, Func: f}
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	go func() {
+		for {
+			now := time.Now().In(s.location)
+			next := now.Truncate(time.Minute).Add(time.Minute)
+			time.Sleep(next.Sub(now))
+			s.mu.Lock()
+			due := []*Job{}
+			for _, job := range s.jobs {
+				if job.Schedule.Match(next) {
+					due = append(due, job)
+				}
+			}
+			s.mu.Unlock()
+			for _, job := range due {
+				go s.run(job)
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	if !s.begin(job) {
+		return ErrJobRunning
+	}
+	go s.execute(job, true)
+	return nil
+}
+
+func (s *Scheduler) run(job *Job) {
+	if !s.begin(job) {
+		log.Printf("info: job %s skipped because it is still running\n", job.Name)
+		return
+	}
+	s.execute(job, false)
+}
+
+func (s *Scheduler) begin(job *Job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job.running {
+		return false
+	}
+	job.running = true
+	return true
+}
+
+func (s *Scheduler) execute(job *Job, manual bool) {
+	r := Run{Start: time.Now(), Manual: manual}
+	func() {
+		defer func() {
+			if v := recover(); v != nil {
+				r.Err = fmt.Errorf("panic: %v", v)
+			}
+		}()
+		r.Err = job.Func()
+	}()
+	r.End = time.Now()
+	if r.Err != nil {
+		log.Printf("error: job %s | %s\n", job.Name, r.Err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.running = false
+	job.history = append(job.history, r)
+	if len(job.history) > MAX_HISTORY {
+		job.history = job.history[len(job.history)-MAX_HISTORY:]
+	}
+}
+
+func (s *Scheduler) Jobs() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().In(s.location)
+	statuses := []Status{}
+	for _, job := range s.jobs {
+		status := Status{Name: job.Name, Schedule: job.Schedule.String(), Running: job.running}
+		status.Next, _ = job.Schedule.Next(now)
+		if len(job.history) > 0 {
+			last := job.history[len(job.history)-1]
+			status.Last = &last
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+func (s *Scheduler) History(name string) ([]Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	history := make([]Run, len(job.history))
+	for i, r := range job.history {
+		history[len(history)-1-i] = r
+	}
+	return history, nil
+}
@@ -3,11 +3,14 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"time"
 
 	"./opprocessor"
+	"./utils"
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/comail/colog"
 	_ "github.com/go-sql-driver/mysql"
@@ -17,17 +20,41 @@ import (
 )
 
 func main() {
+	issueTickets := flag.Int("issue-tickets", 0, "issue the given number of tickets and exit")
+	ticketKind := flag.String("ticket-kind", string(utils.TICKET_MONTH), "kind of issued tickets (7d, 30d, permanent, trial)")
+	cleanupDryRun := flag.Bool("cleanup-dry-run", false, "print what the group cleanup would do and exit")
+	flag.Parse()
+
 	startProgramTime := time.Now()
 	db, _ := sql.Open("mysql", "tamaki:"+os.Getenv("MYSQL_PASSWORD")+"@tcp(10.25.96.4:3306)/tamaki?parseTime=true&loc=Asia%2FTokyo")
 	defer db.Close()
 
+	if *issueTickets > 0 {
+		codes, err := utils.Init(nil, db).IssueTickets(utils.TicketKind(*ticketKind), *issueTickets, utils.TICKET_VALIDITY)
+		if err != nil {
+			log.Fatalln("error:", err.Error())
+		}
+		for _, code := range codes {
+			fmt.Println(code)
+		}
+		return
+	}
+
 	client, _ := getClient(db)
 	ctx := context.Background()
 
+	if *cleanupDryRun {
+		plan, err := utils.Init(client, db).PlanCleanup()
+		if err != nil {
+			log.Fatalln("error:", err.Error())
+		}
+		printCleanupPlan(plan)
+		return
+	}
+
 	initLogger()
 
 	opProcessor := opprocessor.Init(client, ctx, db, startProgramTime)
-	go opProcessor.ClearKickedCount()
 	opProcessor.Run()
 }
 
@@ -55,6 +82,22 @@ func getClient(db *sql.DB) ([]*linethrift.TalkServiceClient, []*thrift.THttpClie
 	return client, transport
 }
 
+func printCleanupPlan(plan *utils.CleanupPlan) {
+	fmt.Printf("[Groups to leave] %d\n", len(plan.Groups))
+	for _, group := range plan.Groups {
+		fmt.Printf("%s\tinviter=%s\texpair=%s\n", group.Gid, group.Inviter, group.Expair.Format("2006-01-02"))
+	}
+	owners := plan.Owners()
+	fmt.Printf("\n[Affected inviters] %d\n", len(owners))
+	for _, mid := range owners {
+		fmt.Println(mid)
+	}
+	fmt.Printf("\n[Invitations to reject] %d\n", len(plan.Invitations))
+	for _, invitation := range plan.Invitations {
+		fmt.Printf("%s\tclient=%d\n", invitation.Gid, invitation.Client+1)
+	}
+}
+
 func initLogger() {
 	colog.SetDefaultLevel(colog.LDebug)
 	colog.SetMinLevel(colog.LTrace)
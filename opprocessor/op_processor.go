@@ -27,25 +27,53 @@ type OpProcessor struct {
 	TalkProcessor    *talkprocessor.TalkProcessor
 	StartProgramTime time.Time
 	Kicker           []*linethrift.TalkServiceClient
-	Kicked           map[string]map[string]uint
+	Kicked           *kickedCountList
+}
+
+type kickedCountList struct {
+	sync.Mutex
+	list map[string]map[string]uint
 }
 
 func Init(client []*linethrift.TalkServiceClient, ctx context.Context, db *sql.DB, startProgramTime time.Time) *OpProcessor {
 	poll, _ := lineapi.NewPollingManager(client[0])
 	u := utils.Init(client, db)
 	tp := talkprocessor.Init(u, db, ctx, startProgramTime)
-	go tp.ClearExecutedList()
 	kicker := make([]*linethrift.TalkServiceClient, len(client)-1)
 	copy(kicker, client[1:])
-	kicked := map[string]map[string]uint{}
-	return &OpProcessor{client, ctx, poll, db, u, tp, startProgramTime, kicker, kicked}
+	kicked := &kickedCountList{list: map[string]map[string]uint{}}
+	op := &OpProcessor{client, ctx, poll, db, u, tp, startProgramTime, kicker, kicked}
+	schedule := talkprocessor.JobSchedule(talkprocessor.JOB_KICKEDCOUNT, talkprocessor.JOB_KICKEDCOUNT_SCHEDULE)
+	err := tp.Jobs.Add(talkprocessor.JOB_KICKEDCOUNT, schedule, func() error {
+		op.ClearKickedCount()
+		return nil
+	})
+	if err != nil {
+		log.Fatalln("error:", err.Error())
+	}
+	tp.Jobs.Start()
+	tp.CmdProcessor.RestoreLockdowns()
+	return op
 }
 
 func (p *OpProcessor) ClearKickedCount() {
-	for {
-		time.Sleep(time.Minute * 2)
-		p.Kicked = map[string]map[string]uint{}
+	p.Kicked.Lock()
+	p.Kicked.list = map[string]map[string]uint{}
+	p.Kicked.Unlock()
+}
+
+func (p *OpProcessor) countKick(gid string, mid string) bool {
+	p.Kicked.Lock()
+	defer p.Kicked.Unlock()
+	if _, ok := p.Kicked.list[gid]; !ok {
+		p.Kicked.list[gid] = map[string]uint{}
+	}
+	if p.Kicked.list[gid][mid] == 2 {
+		p.Kicked.list[gid][mid] = 0
+		return true
 	}
+	p.Kicked.list[gid][mid]++
+	return false
 }
 
 func (p *OpProcessor) Run() {
@@ -54,6 +82,7 @@ func (p *OpProcessor) Run() {
 	p.Poll.SetOperationProcessor(linethrift.OpType_NOTIFIED_UPDATE_GROUP, p.updatedGroup)
 	p.Poll.SetOperationProcessor(linethrift.OpType_NOTIFIED_KICKOUT_FROM_GROUP, p.kickedoutFromGroup)
 	p.Poll.SetOperationProcessor(linethrift.OpType_NOTIFIED_INVITE_INTO_ROOM, p.invitedIntoRoom)
+	p.Poll.SetOperationProcessor(linethrift.OpType_NOTIFIED_ACCEPT_GROUP_INVITATION, p.acceptedGroupInvitation)
 	p.Poll.StartPolling()
 }
 
@@ -68,10 +97,29 @@ func (p *OpProcessor) invitedIntoGroup(operation *linethrift.Operation) {
 			log.Println("error:", err.Error())
 			return
 		}
+		isTrial := false
+		if !isContainsUser {
+			isTrial = p.startTrial(operation.Param2)
+			isContainsUser = isTrial
+		}
+		if isContainsUser && !p.withinGroupQuota(operation.Param1, operation.Param2) {
+			if isTrial {
+				p.cancelTrial(operation.Param2)
+			}
+			p.Client[0].RejectGroupInvitation(p.Ctx, 0, operation.Param1)
+			return
+		}
 		if isContainsUser {
 			group, _ := p.Client[0].GetGroup(p.Ctx, operation.Param1)
 			if len(group.Members) < 493 {
-				p.Client[0].AcceptGroupInvitation(p.Ctx, 0, operation.Param1)
+				err := p.Client[0].AcceptGroupInvitation(p.Ctx, 0, operation.Param1)
+				if err != nil {
+					log.Println("error: メインアカウント参加失敗")
+					if isTrial {
+						p.cancelTrial(operation.Param2)
+					}
+					return
+				}
 				group, err := p.Client[0].GetGroup(p.Ctx, operation.Param1)
 				if err != nil {
 					log.Println("error: メインアカウント参加失敗")
@@ -98,13 +146,7 @@ func (p *OpProcessor) invitedIntoGroup(operation *linethrift.Operation) {
 						0,
 						p.Utils.GenerateTextMessage(
 							operation.Param1,
-							"認証完了なのです！\n"+
-								"さあ、張り切って参りましょうかっ\n\n"+
-								"※台詞はbeatmania IIDX 26 Rootageのシステムボイスを参考にしています。\n\n"+
-								"[作者]\n"+
-								"のえる\n"+
-								"http://line.me/ti/p/%40djv5227g\n\n"+
-								"※本BOTは非公式です。",
+							p.Utils.T(operation.Param1, "greeting"),
 						),
 					)
 				}()
@@ -125,7 +167,7 @@ func (p *OpProcessor) invitedIntoGroup(operation *linethrift.Operation) {
 				}
 				if !isContainsGroup {
 					_, err = p.DB.Exec(
-						`INSERT INTO protections(id, inviter) VALUES (?, ?)`,
+						`INSERT INTO protections(id, inviter, joined) VALUES (?, ?, TRUE)`,
 						operation.Param1,
 						operation.Param2,
 					)
@@ -134,7 +176,7 @@ func (p *OpProcessor) invitedIntoGroup(operation *linethrift.Operation) {
 					}
 				} else {
 					_, err = p.DB.Exec(
-						`UPDATE protections SET inviter = ? WHERE id = ?`,
+						`UPDATE protections SET inviter = ?, joined = TRUE WHERE id = ?`,
 						operation.Param2,
 						operation.Param1,
 					)
@@ -143,11 +185,27 @@ func (p *OpProcessor) invitedIntoGroup(operation *linethrift.Operation) {
 					}
 				}
 				log.Printf("info: Joined -> %s(%s)\n", operation.Param1, group.Name)
+				p.TalkProcessor.StartSetupWizard(operation.Param1, operation.Param2)
+				if isTrial {
+					p.Client[0].SendMessage(p.Ctx, 0, p.Utils.GenerateTextMessage(
+						operation.Param2,
+						p.TalkProcessor.CmdProcessor.TrialStartedText(operation.Param2),
+					))
+				}
+			} else if isTrial {
+				p.cancelTrial(operation.Param2)
 			}
 		} else {
-			p.Client[0].RejectGroupInvitation(p.Ctx, 0, operation.Param1)
+			p.rejectInvitation(operation.Param1, operation.Param2)
+		}
+	} else {
+		if p.isSuspended(operation.Param1) {
+			return
+		}
+		banned := p.cancelBannedInvitees(operation.Param1, strings.Split(operation.Param3, "\x1e"))
+		if p.isAllowedInviter(operation.Param1, operation.Param2) {
+			return
 		}
-	} else if okperm, _ := p.Utils.HasGroupPermission(operation.Param1, operation.Param2); !okperm && !p.Utils.IsBotMid(operation.Param2) {
 		var isProtected bool
 		err := p.DB.QueryRow(
 			`SELECT exists(SELECT 1 FROM protections WHERE inviteprotection = TRUE AND id = ?)`,
@@ -168,8 +226,13 @@ func (p *OpProcessor) invitedIntoGroup(operation *linethrift.Operation) {
 				kicked := strings.Split(operation.Param3, "\x1e")
 				i := 0
 				for _, target := range kicked {
-					if ok, _ := p.Utils.HasGroupPermission(operation.Param1, operation.Param3); !ok {
-						kickers[i].CancelGroupInvitation(p.Ctx, 0, operation.Param1, []string{target})
+					if !banned[target] && !p.isAllowedInviter(operation.Param1, target) {
+						err := kickers[i].CancelGroupInvitation(p.Ctx, 0, operation.Param1, []string{target})
+						if err != nil {
+							log.Println("error:", err.Error())
+						} else {
+							p.TalkProcessor.CmdProcessor.AddLockdownCancelled(operation.Param1, 1)
+						}
 						if kickerSize-1 == i {
 							i = 0
 							time.Sleep(time.Millisecond * 500)
@@ -183,13 +246,134 @@ func (p *OpProcessor) invitedIntoGroup(operation *linethrift.Operation) {
 	}
 }
 
+func (p *OpProcessor) isSuspended(gid string) bool {
+	isSuspended, err := p.Utils.IsSuspended(gid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return false
+	}
+	return isSuspended
+}
+
+func (p *OpProcessor) rejectInvitation(gid string, mid string) {
+	if !p.TalkProcessor.RejectionLimiter.Allow(mid) {
+		p.Client[0].RejectGroupInvitation(p.Ctx, 0, gid)
+		return
+	}
+	_, err := p.Client[0].SendMessage(p.Ctx, 0, p.Utils.GenerateTextMessage(
+		mid,
+		p.Utils.T(mid, "invitation.rejected", utils.TICKET_PREFIX),
+	))
+	if err == nil {
+		p.Client[0].RejectGroupInvitation(p.Ctx, 0, gid)
+		return
+	}
+	log.Println("error:", err.Error())
+	if !p.TalkProcessor.FallbackLimiter.Allow("*") {
+		p.Client[0].RejectGroupInvitation(p.Ctx, 0, gid)
+		return
+	}
+	if err := p.Client[0].AcceptGroupInvitation(p.Ctx, 0, gid); err != nil {
+		log.Println("error:", err.Error())
+		p.Client[0].RejectGroupInvitation(p.Ctx, 0, gid)
+		return
+	}
+	p.Client[0].SendMessage(p.Ctx, 0, p.Utils.GenerateTextMessage(
+		gid,
+		p.Utils.T(gid, "invitation.rejected", utils.TICKET_PREFIX),
+	))
+	p.Client[0].LeaveGroup(p.Ctx, 0, gid)
+}
+
+func (p *OpProcessor) startTrial(mid string) bool {
+	ok, expair, err := p.Utils.StartTrial(mid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return false
+	}
+	if ok {
+		log.Printf("info: Trial started -> %s(%s)\n", mid, expair.Format("2006-01-02"))
+	}
+	return ok
+}
+
+func (p *OpProcessor) cancelTrial(mid string) {
+	if err := p.Utils.CancelTrial(mid); err != nil {
+		log.Println("error:", err.Error())
+		return
+	}
+	log.Printf("info: Trial canceled -> %s\n", mid)
+}
+
+func (p *OpProcessor) withinGroupQuota(gid string, mid string) bool {
+	plan, err := p.Utils.GetPlan(mid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return true
+	}
+	count, err := p.Utils.CountProtectedGroups(mid, gid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return true
+	}
+	if plan.AllowsGroups(count) {
+		return true
+	}
+	if !p.TalkProcessor.RejectionLimiter.Allow(mid) {
+		return false
+	}
+	p.Client[0].SendMessage(p.Ctx, 0, p.Utils.GenerateTextMessage(
+		mid,
+		p.TalkProcessor.CmdProcessor.QuotaExceededText(mid, plan),
+	))
+	return false
+}
+
+func (p *OpProcessor) cancelBannedInvitees(gid string, invitees []string) map[string]bool {
+	banned := map[string]bool{}
+	for _, invitee := range invitees {
+		isBanned, err := p.Utils.IsBanned(gid, invitee)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		if !isBanned {
+			continue
+		}
+		err = p.Utils.GetRandomKicker().CancelGroupInvitation(p.Ctx, 0, gid, []string{invitee})
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		banned[invitee] = true
+	}
+	return banned
+}
+
+func (p *OpProcessor) isAllowedInviter(gid string, mid string) bool {
+	if p.Utils.IsBotMid(mid) {
+		return true
+	}
+	if ok, err := p.Utils.HasGroupPermission(gid, mid); err != nil {
+		log.Println("error:", err.Error())
+	} else if ok {
+		return true
+	}
+	isTrusted, err := p.Utils.IsTrustedInviter(gid, mid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return false
+	}
+	return isTrusted
+}
+
 func (p *OpProcessor) receivedMessage(operation *linethrift.Operation) {
 	message := operation.Message
 	p.TalkProcessor.Process(message)
 }
 
 func (p *OpProcessor) updatedGroup(operation *linethrift.Operation) {
-	if !p.Utils.IsBotMid(operation.Param2) {
+	if !p.Utils.IsBotMid(operation.Param2) && !p.isSuspended(operation.Param1) {
 		groupattr, _ := strconv.Atoi(operation.Param3)
 		switch int64(groupattr) {
 		case int64(linethrift.GroupAttribute_NAME):
@@ -345,6 +529,9 @@ func (p *OpProcessor) deleteClient(s []*linethrift.TalkServiceClient, i int) []*
 }
 
 func (p *OpProcessor) kickedoutFromGroup(operation *linethrift.Operation) {
+	if p.isSuspended(operation.Param1) {
+		return
+	}
 	if !p.Utils.IsBotMid(operation.Param2) {
 		if p.Utils.IsBotMid(operation.Param3) {
 			if ok, _ := p.Utils.HasGroupPermission(operation.Param1, operation.Param2); !ok {
@@ -403,9 +590,10 @@ func (p *OpProcessor) kickedoutFromGroup(operation *linethrift.Operation) {
 					}(client)
 				}
 				wg.Wait()
+				p.Utils.MarkGroupLeft(operation.Param1)
 			}
 		} else if ok, _ := p.Utils.HasGroupPermission(operation.Param1, operation.Param2); !ok {
-			if ok, _ := p.Utils.HasGroupPermission(operation.Param1, operation.Param3); ok {
+			if p.isRejoinTarget(operation.Param1, operation.Param3) {
 				client := p.Utils.GetRandomClient()
 				client.FindAndAddContactsByMid(
 					p.Ctx, 0, operation.Param3,
@@ -420,29 +608,56 @@ func (p *OpProcessor) kickedoutFromGroup(operation *linethrift.Operation) {
 					p.Ctx, 0, operation.Param1,
 					[]string{operation.Param3},
 				)
-			} else {
-				if _, ok := p.Kicked[operation.Param1]; ok {
-					if count, ok := p.Kicked[operation.Param1][operation.Param2]; ok {
-						if count == 2 {
-							p.Kicked[operation.Param1][operation.Param2] = 0
-							client := p.Utils.GetRandomClient()
-							client.KickoutFromGroup(p.Ctx, 0, operation.Param1, []string{operation.Param2})
-						} else {
-							p.Kicked[operation.Param1][operation.Param2]++
-						}
-					} else {
-						p.Kicked[operation.Param1][operation.Param2] = 1
-					}
-				} else {
-					p.Kicked[operation.Param1] = map[string]uint{
-						operation.Param2: 1,
-					}
-				}
+			} else if p.countKick(operation.Param1, operation.Param2) {
+				client := p.Utils.GetRandomClient()
+				client.KickoutFromGroup(p.Ctx, 0, operation.Param1, []string{operation.Param2})
 			}
 		}
 	}
 }
 
+func (p *OpProcessor) isRejoinTarget(gid string, mid string) bool {
+	if ok, _ := p.Utils.HasGroupPermission(gid, mid); ok {
+		return true
+	}
+	isProtected, err := p.Utils.IsProtectedMember(gid, mid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return false
+	}
+	return isProtected
+}
+
+func (p *OpProcessor) acceptedGroupInvitation(operation *linethrift.Operation) {
+	if p.isSuspended(operation.Param1) {
+		return
+	}
+	if isBanned, err := p.Utils.IsBanned(operation.Param1, operation.Param2); err != nil {
+		log.Println("error:", err.Error())
+	} else if isBanned {
+		err := p.Utils.GetRandomKicker().KickoutFromGroup(p.Ctx, 0, operation.Param1, []string{operation.Param2})
+		if err != nil {
+			log.Println("error:", err.Error())
+		}
+		return
+	}
+	if !p.TalkProcessor.CmdProcessor.IsLockdown(operation.Param1) {
+		return
+	}
+	if p.Utils.IsBotMid(operation.Param2) {
+		return
+	}
+	if ok, _ := p.Utils.HasGroupPermission(operation.Param1, operation.Param2); ok {
+		return
+	}
+	err := p.Utils.GetRandomClient().KickoutFromGroup(p.Ctx, 0, operation.Param1, []string{operation.Param2})
+	if err != nil {
+		log.Println("error:", err.Error())
+		return
+	}
+	p.TalkProcessor.CmdProcessor.AddLockdownKicked(operation.Param1, 1)
+}
+
 func (p *OpProcessor) invitedIntoRoom(operation *linethrift.Operation) {
 	wg := &sync.WaitGroup{}
 	for _, client := range p.Client {
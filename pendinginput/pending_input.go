@@ -0,0 +1,144 @@
+package pendinginput
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mopeneko/linethrift"
+)
+
+const DEFAULT_TIMEOUT = time.Minute * 5
+
+var CANCEL_TEXTS = []string{"キャンセル", "cancel"}
+
+type InputType uint
+
+const (
+	INPUT_CONTACT InputType = 1 << iota
+	INPUT_MENTION
+	INPUT_TEXT
+	INPUT_IMAGE
+)
+
+type Input struct {
+	Type    InputType
+	Message *linethrift.Message
+	Mid     string
+	Mids    []string
+	Text    string
+}
+
+type Request struct {
+	Chat      string
+	Mid       string
+	Type      InputType
+	Timeout   time.Duration
+	Handler   func(input *Input)
+	Accept    func(input *Input) bool
+	OnTimeout func()
+	OnCancel  func()
+}
+
+type key struct {
+	chat string
+	mid  string
+}
+
+type entry struct {
+	request *Request
+	timer   *time.Timer
+}
+
+type Manager struct {
+	mu      sync.Mutex
+	pending map[key]*entry
+}
+
+func New() *Manager {
+	return &Manager{pending: map[key]*entry{}}
+}
+
+func (m *Manager) Wait(request *Request) {
+	if request.Timeout == 0 {
+		request.Timeout = DEFAULT_TIMEOUT
+	}
+	k := key{request.Chat, request.Mid}
+	e := &entry{request: request}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if old, ok := m.pending[k]; ok {
+		old.timer.Stop()
+	}
+	e.timer = time.AfterFunc(request.Timeout, func() {
+		if m.remove(k, e) && request.OnTimeout != nil {
+			request.OnTimeout()
+		}
+	})
+	m.pending[k] = e
+}
+
+func (m *Manager) IsWaiting(chat string, mid string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.pending[key{chat, mid}]
+	return ok
+}
+
+func (m *Manager) Cancel(chat string, mid string) bool {
+	m.mu.Lock()
+	e, ok := m.pending[key{chat, mid}]
+	m.mu.Unlock()
+	if !ok || !m.remove(key{chat, mid}, e) {
+		return false
+	}
+	if e.request.OnCancel != nil {
+		e.request.OnCancel()
+	}
+	return true
+}
+
+func (m *Manager) Process(chat string, mid string, input *Input) bool {
+	k := key{chat, mid}
+	m.mu.Lock()
+	e, ok := m.pending[k]
+	m.mu.Unlock()
+	if !ok {
+		return false
+	}
+	if input.Type == INPUT_TEXT && isCancelText(input.Text) {
+		return m.Cancel(chat, mid)
+	}
+	if e.request.Type&input.Type == 0 {
+		return false
+	}
+	if e.request.Accept != nil && !e.request.Accept(input) {
+		return false
+	}
+	if !m.remove(k, e) {
+		return false
+	}
+	e.request.Handler(input)
+	return true
+}
+
+func isCancelText(text string) bool {
+	for _, cancelText := range CANCEL_TEXTS {
+		if strings.EqualFold(text, cancelText) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) remove(k key, e *entry) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pending[k] != e {
+		return false
+	}
+	e.timer.Stop()
+	delete(m.pending, k)
+	return true
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const PRUNE_INTERVAL = 1000
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type Limiter struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64
+	buckets  map[string]*bucket
+	calls    int
+}
+
+func New(capacity int, per time.Duration) *Limiter {
+	return &Limiter{
+		capacity: float64(capacity),
+		rate:     float64(capacity) / per.Seconds(),
+		buckets:  map[string]*bucket{},
+	}
+}
+
+func Parse(text string) (*Limiter, error) {
+	phrases := strings.SplitN(text, "/", 2)
+	if len(phrases) != 2 {
+		return nil, errors.New("Limit must be written as <count>/<duration>.")
+	}
+	capacity, err := strconv.Atoi(phrases[0])
+	if err != nil || capacity <= 0 {
+		return nil, errors.New("Limit count is wrong.")
+	}
+	per, err := time.ParseDuration(phrases[1])
+	if err != nil || per <= 0 {
+		return nil, errors.New("Limit duration is wrong.")
+	}
+	return New(capacity, per), nil
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.capacity {
+		b.tokens = l.capacity
+	}
+	b.last = now
+}
+
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.prune(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.capacity, last: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (l *Limiter) prune(now time.Time) {
+	l.calls++
+	if l.calls < PRUNE_INTERVAL {
+		return
+	}
+	l.calls = 0
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= l.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,631 @@
+package talkprocessor
+
+import (
+	"strings"
+
+	cmd "../cmdconst"
+	"../cmdparser"
+	"../cmdregistry"
+	"../i18n"
+	"../utils"
+	"github.com/mopeneko/linethrift"
+)
+
+var familyPrefixes = map[cmdregistry.Family][]string{
+	cmdregistry.FAMILY_NORMAL:  {"たまき:", "tamaki:", "💙"},
+	cmdregistry.FAMILY_SETTING: {"設定:", "setting:"},
+}
+
+var familyPrefixKeys = map[cmdregistry.Family]string{
+	cmdregistry.FAMILY_NORMAL:  "prefix.normal",
+	cmdregistry.FAMILY_SETTING: "prefix.setting",
+	cmdregistry.FAMILY_DIRECT:  "prefix.normal",
+}
+
+var roleKeys = map[cmdregistry.Role]string{
+	cmdregistry.ROLE_GROUPADMIN: "role.groupadmin",
+	cmdregistry.ROLE_INVITER:    "role.inviter",
+	cmdregistry.ROLE_ADMIN:      "role.admin",
+}
+
+type commandRegisterer struct {
+	*cmdregistry.Registry
+}
+
+func (r commandRegisterer) Register(command *cmdregistry.Command) {
+	if english, ok := cmd.ENGLISH_NAMES[command.Name]; ok {
+		command.Aliases = append(command.Aliases, english)
+	}
+	r.Registry.Register(command)
+}
+
+func (p *TalkProcessor) registerCommands() {
+	r := commandRegisterer{p.Registry}
+	cp := p.CmdProcessor
+
+	for family := range familyPrefixKeys {
+		family := family
+		r.Register(&cmdregistry.Command{
+			Name:   cmd.NORMAL_HELP,
+			Family: family,
+			Role:   cmdregistry.ROLE_EVERYONE,
+			Help:   "help.help",
+			Handler: func(message *linethrift.Message, args []string) {
+				p.SendHelp(message, family)
+			},
+		})
+	}
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_CHECKSTATUS,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_EVERYONE,
+		Help:    "help.status",
+		Handler: func(message *linethrift.Message, args []string) { cp.SendStatus(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_CHECKPERMISSION,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_EVERYONE,
+		Help:    "help.permission",
+		Handler: func(message *linethrift.Message, args []string) { cp.CheckPermission(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_CHECKSPEED,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_EVERYONE,
+		Help:    "help.speed",
+		Handler: func(message *linethrift.Message, args []string) { cp.CheckSendSpeed(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_CHECKKICKERS,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Help:    "help.kickers",
+		Handler: func(message *linethrift.Message, args []string) { cp.CheckKickers(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_LEAVEBOTS,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Help:    "help.leave",
+		Handler: func(message *linethrift.Message, args []string) { cp.LeaveBots(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_KICK,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Help:    "help.kick",
+		Handler: p.withTargets(cp.KickMembers),
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_BAN,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Feature: string(utils.FEATURE_BAN),
+		Help:    "help.ban",
+		Handler: p.withTargets(cp.BanMembers),
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_UNBAN,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Help:    "help.unban",
+		Handler: p.withTargets(cp.UnbanMembers),
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_PROTECT,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Help:    "help.protect",
+		Handler: p.withTargets(cp.ProtectMembers),
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_UNPROTECT,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Help:    "help.unprotect",
+		Handler: p.withTargets(cp.UnprotectMembers),
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.NORMAL_PROMOTE,
+		Family:  cmdregistry.FAMILY_NORMAL,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Help:    "help.promote",
+		Handler: p.withTargets(cp.PromoteMember),
+	})
+
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.SETTING_CHECK,
+		Family:  cmdregistry.FAMILY_SETTING,
+		Role:    cmdregistry.ROLE_EVERYONE,
+		Help:    "help.check",
+		Handler: func(message *linethrift.Message, args []string) { cp.CheckSetting(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.SETTING_CHECKTRUSTEDINVITERS,
+		Family:  cmdregistry.FAMILY_SETTING,
+		Role:    cmdregistry.ROLE_EVERYONE,
+		Help:    "help.trusted",
+		Handler: func(message *linethrift.Message, args []string) { cp.CheckTrustedInviters(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_NAME,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.switch", Type: cmdregistry.ARG_SWITCH}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.namelock",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.SwitchNameProtection(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_ICON,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.switch", Type: cmdregistry.ARG_SWITCH}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.iconlock",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.SwitchIconProtection(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_URL,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.switch", Type: cmdregistry.ARG_SWITCH}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.urllock",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.SwitchURLProtection(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_INVITE,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.switch", Type: cmdregistry.ARG_SWITCH}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.invitelock",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.SwitchInviteProtection(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_BULK,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.bulk", Rest: true}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.bulk",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.BulkSetting(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.NORMAL_CHANGESUBADMIN,
+		Family: cmdregistry.FAMILY_SETTING,
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.subadmin",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.ChangeSubAdmin(message)
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_ADDTRUSTEDINVITER,
+		Family: cmdregistry.FAMILY_SETTING,
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.addtrusted",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.AddTrustedInviter(message)
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_REMOVETRUSTEDINVITER,
+		Family: cmdregistry.FAMILY_SETTING,
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.removetrusted",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.RemoveTrustedInviter(message)
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.SETTING_LOCKDOWN,
+		Family:  cmdregistry.FAMILY_SETTING,
+		Args:    []cmdregistry.Arg{{Name: "arg.minutes", Type: cmdregistry.ARG_INT, Optional: true}},
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Feature: string(utils.FEATURE_LOCKDOWN),
+		Help:    "help.lockdown",
+		Handler: func(message *linethrift.Message, args []string) {
+			minutesText := ""
+			if len(args) > 0 {
+				minutesText = args[0]
+			}
+			cp.StartLockdown(message, minutesText)
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.SETTING_UNLOCKDOWN,
+		Family:  cmdregistry.FAMILY_SETTING,
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Help:    "help.unlockdown",
+		Handler: func(message *linethrift.Message, args []string) { cp.StopLockdown(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.SETTING_ADDSCHEDULE,
+		Family:  cmdregistry.FAMILY_SETTING,
+		Args:    []cmdregistry.Arg{{Name: "arg.setting"}, {Name: "arg.range", Rest: true}},
+		Role:    cmdregistry.ROLE_GROUPADMIN,
+		Feature: string(utils.FEATURE_SCHEDULE),
+		Help:    "help.addschedule",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.AddSchedule(message, args[0], args[1])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_REMOVESCHEDULE,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.number", Type: cmdregistry.ARG_INT}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.removeschedule",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.RemoveSchedule(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_PRESET,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.preset"}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.preset",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.ApplyPreset(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_LANGUAGE,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.language"}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.language",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.ChangeLanguage(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.SETTING_CHECKPREFIXES,
+		Family:  cmdregistry.FAMILY_SETTING,
+		Role:    cmdregistry.ROLE_EVERYONE,
+		Help:    "help.prefixes",
+		Handler: func(message *linethrift.Message, args []string) { p.CheckPrefixes(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_ADDPREFIX,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.prefixfamily"}, {Name: "arg.prefix"}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.addprefix",
+		Handler: func(message *linethrift.Message, args []string) {
+			p.AddPrefix(message, args[0], args[1])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_REMOVEPREFIX,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.prefixfamily"}, {Name: "arg.prefix"}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.removeprefix",
+		Handler: func(message *linethrift.Message, args []string) {
+			p.RemovePrefix(message, args[0], args[1])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_REPLACEPREFIX,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.switch", Type: cmdregistry.ARG_SWITCH}},
+		Role:   cmdregistry.ROLE_GROUPADMIN,
+		Help:   "help.replaceprefix",
+		Handler: func(message *linethrift.Message, args []string) {
+			p.SwitchPrefixReplace(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.SETTING_TEMPLATES,
+		Family:  cmdregistry.FAMILY_SETTING,
+		Role:    cmdregistry.ROLE_INVITER,
+		Help:    "help.templates",
+		Handler: func(message *linethrift.Message, args []string) { cp.ListTemplates(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.SETTING_COPY,
+		Family: cmdregistry.FAMILY_SETTING,
+		Args:   []cmdregistry.Arg{{Name: "arg.number", Type: cmdregistry.ARG_INT}},
+		Role:   cmdregistry.ROLE_INVITER,
+		Help:   "help.copy",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.CopySettings(message, args[0])
+		},
+	})
+
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.DIRECT_GROUPS,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_EVERYONE,
+		Help:    "help.groups",
+		Handler: func(message *linethrift.Message, args []string) { p.ListManagedGroups(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.DIRECT_SELECT,
+		Family: cmdregistry.FAMILY_DIRECT,
+		Args:   []cmdregistry.Arg{{Name: "arg.number", Type: cmdregistry.ARG_INT}},
+		Role:   cmdregistry.ROLE_EVERYONE,
+		Help:   "help.select",
+		Handler: func(message *linethrift.Message, args []string) {
+			p.SelectGroup(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.DIRECT_DESELECT,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_EVERYONE,
+		Help:    "help.deselect",
+		Handler: func(message *linethrift.Message, args []string) { p.DeselectGroup(message) },
+	})
+
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.DIRECT_ISSUETICKETS,
+		Family: cmdregistry.FAMILY_DIRECT,
+		Args: []cmdregistry.Arg{
+			{Name: "arg.ticketkind", Optional: true},
+			{Name: "arg.count", Type: cmdregistry.ARG_INT, Optional: true},
+		},
+		Role: cmdregistry.ROLE_ADMIN,
+		Help: "help.issuetickets",
+		Handler: func(message *linethrift.Message, args []string) {
+			p.IssueTickets(message, args)
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.DIRECT_LISTTICKETS,
+		Family: cmdregistry.FAMILY_DIRECT,
+		Args:   []cmdregistry.Arg{{Name: "arg.count", Type: cmdregistry.ARG_INT, Optional: true}},
+		Role:   cmdregistry.ROLE_ADMIN,
+		Help:   "help.listtickets",
+		Handler: func(message *linethrift.Message, args []string) {
+			p.ListTickets(message, args)
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.ADMIN_GRANT,
+		Family: cmdregistry.FAMILY_DIRECT,
+		Args:   []cmdregistry.Arg{{Name: "arg.days"}},
+		Role:   cmdregistry.ROLE_ADMIN,
+		Help:   "help.grant",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.GrantPermission(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.ADMIN_EXTEND,
+		Family: cmdregistry.FAMILY_DIRECT,
+		Args:   []cmdregistry.Arg{{Name: "arg.days", Type: cmdregistry.ARG_INT}},
+		Role:   cmdregistry.ROLE_ADMIN,
+		Help:   "help.extend",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.ExtendPermission(message, args[0])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_REVOKE,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.revoke",
+		Handler: func(message *linethrift.Message, args []string) { cp.RevokePermission(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_LOOKUP,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.lookup",
+		Handler: func(message *linethrift.Message, args []string) { cp.LookupUser(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_GROUPS,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.allgroups",
+		Handler: func(message *linethrift.Message, args []string) { cp.ListAllGroups(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_FLEET,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.fleet",
+		Handler: func(message *linethrift.Message, args []string) { cp.CheckFleet(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_ADDADMIN,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.addadmin",
+		Handler: func(message *linethrift.Message, args []string) { cp.AddAdmin(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_REMOVEADMIN,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.removeadmin",
+		Handler: func(message *linethrift.Message, args []string) { cp.RemoveAdmin(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_LISTADMINS,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.admins",
+		Handler: func(message *linethrift.Message, args []string) { cp.ListAdmins(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:   cmd.ADMIN_BROADCAST,
+		Family: cmdregistry.FAMILY_DIRECT,
+		Args:   []cmdregistry.Arg{{Name: "arg.broadcastfilter"}, {Name: "arg.text", Rest: true}},
+		Role:   cmdregistry.ROLE_ADMIN,
+		Help:   "help.broadcast",
+		Handler: func(message *linethrift.Message, args []string) {
+			cp.Broadcast(message, args[0], args[1])
+		},
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_JOBS,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.jobs",
+		Handler: func(message *linethrift.Message, args []string) { p.ListJobs(message) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_RUNJOB,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Args:    []cmdregistry.Arg{{Name: "arg.job"}},
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.runjob",
+		Handler: func(message *linethrift.Message, args []string) { p.RunJob(message, args[0]) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_JOBHISTORY,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Args:    []cmdregistry.Arg{{Name: "arg.job"}},
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.jobhistory",
+		Handler: func(message *linethrift.Message, args []string) { p.JobHistory(message, args[0]) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_SETPLAN,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Args:    []cmdregistry.Arg{{Name: "arg.plan"}},
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.setplan",
+		Handler: func(message *linethrift.Message, args []string) { cp.SetPlan(message, args[0]) },
+	})
+	r.Register(&cmdregistry.Command{
+		Name:    cmd.ADMIN_CLEANUP,
+		Family:  cmdregistry.FAMILY_DIRECT,
+		Role:    cmdregistry.ROLE_ADMIN,
+		Help:    "help.cleanup",
+		Handler: func(message *linethrift.Message, args []string) { cp.PreviewCleanup(message) },
+	})
+}
+
+func (p *TalkProcessor) withTargets(handler func(*linethrift.Message, []string)) cmdregistry.Handler {
+	return func(message *linethrift.Message, args []string) {
+		targets := p.getTargets(message)
+		if len(targets) == 0 && p.Utils.IsRemote(message) {
+			p.CmdProcessor.WaitForTargets(message, handler)
+			return
+		}
+		if len(targets) == 0 {
+			p.Utils.ReplyLocalized(p.Ctx, message, "moderation.notarget")
+			return
+		}
+		handler(message, targets)
+	}
+}
+
+func (p *TalkProcessor) hasRole(gid string, mid string, role cmdregistry.Role) bool {
+	switch role {
+	case cmdregistry.ROLE_EVERYONE:
+		return true
+	case cmdregistry.ROLE_GROUPADMIN:
+		ok, _ := p.Utils.HasGroupPermission(gid, mid)
+		return ok
+	case cmdregistry.ROLE_INVITER:
+		ok, _ := p.Utils.IsGroupInviter(gid, mid)
+		return ok
+	case cmdregistry.ROLE_ADMIN:
+		return p.Utils.IsAdmin(mid)
+	}
+	return false
+}
+
+func (p *TalkProcessor) explainsParseError(message *linethrift.Message, family cmdregistry.Family, prefix string) bool {
+	if _, ok := p.Registry.Lookup(family, cmdparser.PeekName(stripMentions(message), prefix)); ok {
+		return true
+	}
+	checked := map[cmdregistry.Role]bool{}
+	for _, command := range p.Registry.Commands(family) {
+		if command.Role == cmdregistry.ROLE_EVERYONE || checked[command.Role] {
+			continue
+		}
+		if p.hasRole(message.To, message.From, command.Role) {
+			return true
+		}
+		checked[command.Role] = true
+	}
+	return false
+}
+
+func (p *TalkProcessor) dispatch(message *linethrift.Message, family cmdregistry.Family, prefix string) {
+	language := p.Utils.GetLanguage(message.To)
+	parsed, err := cmdparser.Parse(stripMentions(message), prefix)
+	if err != nil {
+		if !p.explainsParseError(message, family, prefix) || !p.allowCommand(message) {
+			return
+		}
+		p.Utils.ReplyLocalized(p.Ctx, message, "command.unreadable", err.(*cmdparser.ParseError).Localize(language))
+		return
+	}
+	command, ok := p.Registry.Lookup(family, parsed.Name)
+	if !ok {
+		p.suggestCommands(message, family, parsed.Name, language)
+		return
+	}
+	if !p.hasRole(message.To, message.From, command.Role) {
+		if p.allowCommand(message) {
+			p.Utils.ReplyLocalized(p.Ctx, message, "command.forbidden", i18n.T(language, roleKeys[command.Role]))
+		}
+		return
+	}
+	if !p.allowCommand(message) {
+		return
+	}
+	if command.Feature != "" && !p.CmdProcessor.AllowFeature(message, utils.Feature(command.Feature)) {
+		return
+	}
+	args, err := command.ParseArgs(parsed.Args)
+	if err != nil {
+		p.Utils.ReplyLocalized(
+			p.Ctx, message, "command.invalid",
+			err.(*cmdregistry.ArgError).Localize(language),
+			command.Usage(p.displayPrefix(message.To, family, language), language),
+		)
+		return
+	}
+	command.Handler(message, args)
+}
+
+func (p *TalkProcessor) suggestCommands(message *linethrift.Message, family cmdregistry.Family, name string, language string) {
+	suggestions := p.Registry.Suggest(family, name, language, func(command *cmdregistry.Command) bool {
+		return p.hasRole(message.To, message.From, command.Role)
+	})
+	if len(suggestions) == 0 || !p.SuggestLimiter.Allow(p.Utils.ReplyChat(message)) {
+		return
+	}
+	prefix := p.displayPrefix(message.To, family, language)
+	names := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		names[i] = prefix + suggestion.Name
+	}
+	p.Utils.ReplyLocalized(
+		p.Ctx, message, "command.suggest",
+		name, strings.Join(names, i18n.T(language, "common.separator")),
+	)
+}
+
+func (p *TalkProcessor) SendHelp(message *linethrift.Message, family cmdregistry.Family) {
+	language := p.Utils.GetLanguage(message.To)
+	text := i18n.T(language, "help.header")
+	for _, command := range p.Registry.Commands(family) {
+		if !p.hasRole(message.To, message.From, command.Role) {
+			continue
+		}
+		text += "\n" + command.Usage(p.displayPrefix(message.To, family, language), language)
+		if command.Help != "" {
+			text += "\n  " + i18n.T(language, command.Help)
+		}
+	}
+	p.Utils.Reply(p.Ctx, message, text)
+}
@@ -0,0 +1,116 @@
+package talkprocessor
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"../jobscheduler"
+	"github.com/mopeneko/linethrift"
+)
+
+const (
+	JOB_CLEANGROUPS = "cleangroups"
+	JOB_SCHEDULES   = "schedules"
+	JOB_REMINDERS   = "reminders"
+	JOB_KICKEDCOUNT = "kickedcount"
+
+	JOB_CLEANGROUPS_SCHEDULE = "0 4 * * *"
+	JOB_SCHEDULES_SCHEDULE   = "* * * * *"
+	JOB_REMINDERS_SCHEDULE   = "0 * * * *"
+	JOB_KICKEDCOUNT_SCHEDULE = "*/2 * * * *"
+)
+
+func newScheduler() *jobscheduler.Scheduler {
+	JST, _ := time.LoadLocation("Asia/Tokyo")
+	return jobscheduler.New(JST)
+}
+
+func JobSchedule(name string, fallback string) string {
+	if spec := os.Getenv("JOB_" + strings.ToUpper(name)); spec != "" {
+		return spec
+	}
+	return fallback
+}
+
+func (p *TalkProcessor) registerJobs() {
+	jobs := []struct {
+		name     string
+		schedule string
+		run      func() error
+	}{
+		{JOB_CLEANGROUPS, JOB_CLEANGROUPS_SCHEDULE, p.Utils.CleanGroups},
+		{JOB_SCHEDULES, JOB_SCHEDULES_SCHEDULE, p.CmdProcessor.ApplySchedules},
+		{JOB_REMINDERS, JOB_REMINDERS_SCHEDULE, p.Utils.SendExpiryReminders},
+	}
+	for _, job := range jobs {
+		err := p.Jobs.Add(job.name, JobSchedule(job.name, job.schedule), job.run)
+		if err != nil {
+			log.Fatalf("error: job %s | %s", job.name, err.Error())
+		}
+	}
+}
+
+func (p *TalkProcessor) formatJobRun(chat string, r *jobscheduler.Run) string {
+	result := p.Utils.T(chat, "job.result.ok")
+	if r.Err != nil {
+		result = p.Utils.T(chat, "job.result.error", r.Err.Error())
+	}
+	trigger := ""
+	if r.Manual {
+		trigger = p.Utils.T(chat, "job.manual")
+	}
+	return p.Utils.T(
+		chat, "job.run",
+		r.Start.Format("01/02 15:04:05"), r.End.Sub(r.Start).Round(time.Millisecond).String(), result, trigger,
+	)
+}
+
+func (p *TalkProcessor) ListJobs(message *linethrift.Message) {
+	text := p.Utils.T(message.To, "job.list")
+	for _, status := range p.Jobs.Jobs() {
+		text += "\n\n" + status.Name + " (" + status.Schedule + ")"
+		if status.Running {
+			text += "\n  " + p.Utils.T(message.To, "job.running")
+		}
+		if status.Last != nil {
+			text += "\n  " + p.formatJobRun(message.To, status.Last)
+		}
+		if !status.Next.IsZero() {
+			text += "\n  " + p.Utils.T(message.To, "job.next", status.Next.Format("01/02 15:04"))
+		}
+	}
+	p.Utils.Reply(p.Ctx, message, text)
+}
+
+func (p *TalkProcessor) RunJob(message *linethrift.Message, name string) {
+	switch err := p.Jobs.Trigger(strings.ToLower(name)); err {
+	case nil:
+		p.Utils.ReplyLocalized(p.Ctx, message, "job.triggered", name)
+	case jobscheduler.ErrJobNotFound:
+		p.Utils.ReplyLocalized(p.Ctx, message, "job.notfound", name)
+	case jobscheduler.ErrJobRunning:
+		p.Utils.ReplyLocalized(p.Ctx, message, "job.busy", name)
+	default:
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+	}
+}
+
+func (p *TalkProcessor) JobHistory(message *linethrift.Message, name string) {
+	history, err := p.Jobs.History(strings.ToLower(name))
+	if err != nil {
+		p.Utils.ReplyLocalized(p.Ctx, message, "job.notfound", name)
+		return
+	}
+	if len(history) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "job.history.empty", name)
+		return
+	}
+	text := p.Utils.T(message.To, "job.history", name)
+	for i := range history {
+		text += "\n" + p.formatJobRun(message.To, &history[i])
+	}
+	p.Utils.Reply(p.Ctx, message, text)
+}
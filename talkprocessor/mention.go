@@ -0,0 +1,104 @@
+package talkprocessor
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/mopeneko/linethrift"
+)
+
+const RECENT_MESSAGE_SIZE = 200
+
+type mentionee struct {
+	S string `json:"S"`
+	E string `json:"E"`
+	M string `json:"M"`
+}
+
+type mention struct {
+	Mentionees []mentionee `json:"MENTIONEES"`
+}
+
+type recentSenderList struct {
+	sync.Mutex
+	senders map[string]map[string]string
+	order   map[string][]string
+}
+
+func parseMentionees(message *linethrift.Message) []mentionee {
+	data, ok := message.ContentMetadata["MENTION"]
+	if !ok {
+		return nil
+	}
+	m := mention{}
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return nil
+	}
+	return m.Mentionees
+}
+
+func stripMentions(message *linethrift.Message) string {
+	mentionees := parseMentionees(message)
+	if len(mentionees) == 0 {
+		return message.Text
+	}
+	sort.Slice(mentionees, func(i, j int) bool {
+		si, _ := strconv.Atoi(mentionees[i].S)
+		sj, _ := strconv.Atoi(mentionees[j].S)
+		return si > sj
+	})
+	text := utf16.Encode([]rune(message.Text))
+	for _, m := range mentionees {
+		start, err := strconv.Atoi(m.S)
+		if err != nil {
+			continue
+		}
+		end, err := strconv.Atoi(m.E)
+		if err != nil || start < 0 || end > len(text) || start > end {
+			continue
+		}
+		text = append(text[:start:start], text[end:]...)
+	}
+	return string(utf16.Decode(text))
+}
+
+func (p *TalkProcessor) recordSender(message *linethrift.Message) {
+	if message.ID == "" {
+		return
+	}
+	p.recentSenders.Lock()
+	defer p.recentSenders.Unlock()
+	if _, ok := p.recentSenders.senders[message.To]; !ok {
+		p.recentSenders.senders[message.To] = map[string]string{}
+	}
+	p.recentSenders.senders[message.To][message.ID] = message.From
+	order := append(p.recentSenders.order[message.To], message.ID)
+	if len(order) > RECENT_MESSAGE_SIZE {
+		delete(p.recentSenders.senders[message.To], order[0])
+		order = order[1:]
+	}
+	p.recentSenders.order[message.To] = order
+}
+
+func (p *TalkProcessor) getTargets(message *linethrift.Message) []string {
+	targets := []string{}
+	seen := map[string]bool{}
+	for _, m := range parseMentionees(message) {
+		if m.M != "" && !seen[m.M] {
+			seen[m.M] = true
+			targets = append(targets, m.M)
+		}
+	}
+	if message.RelatedMessageId != "" {
+		p.recentSenders.Lock()
+		mid, ok := p.recentSenders.senders[message.To][message.RelatedMessageId]
+		p.recentSenders.Unlock()
+		if ok && !seen[mid] {
+			targets = append(targets, mid)
+		}
+	}
+	return targets
+}
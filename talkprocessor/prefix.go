@@ -0,0 +1,235 @@
+package talkprocessor
+
+import (
+	"database/sql"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	cmd "../cmdconst"
+	"../cmdregistry"
+	"../i18n"
+	"github.com/mopeneko/linethrift"
+)
+
+const MAX_PREFIX_LENGTH = 20
+
+var prefixFamilies = map[string]cmdregistry.Family{
+	cmd.PREFIXFAMILY_NORMAL:  cmdregistry.FAMILY_NORMAL,
+	cmd.PREFIXFAMILY_SETTING: cmdregistry.FAMILY_SETTING,
+}
+
+var familyColumns = map[cmdregistry.Family]string{
+	cmdregistry.FAMILY_NORMAL:  "normal",
+	cmdregistry.FAMILY_SETTING: "setting",
+}
+
+type groupPrefixes struct {
+	replace bool
+	custom  map[cmdregistry.Family][]string
+}
+
+type prefixCache struct {
+	sync.Mutex
+	list map[string]*groupPrefixes
+}
+
+func normalizePrefix(prefix string) string {
+	return strings.ToLower(strings.Replace(prefix, "：", ":", -1))
+}
+
+func (p *TalkProcessor) loadPrefixes(gid string) (*groupPrefixes, error) {
+	g := &groupPrefixes{custom: map[cmdregistry.Family][]string{}}
+	err := p.DB.QueryRow(
+		`SELECT prefixreplace FROM protections WHERE id = ?`,
+		gid,
+	).Scan(&g.replace)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	rows, err := p.DB.Query(
+		`SELECT family, prefix FROM prefixes WHERE gid = ? ORDER BY prefix`,
+		gid,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var column, prefix string
+		if err := rows.Scan(&column, &prefix); err != nil {
+			return nil, err
+		}
+		for family, c := range familyColumns {
+			if c == column {
+				g.custom[family] = append(g.custom[family], prefix)
+			}
+		}
+	}
+	return g, rows.Err()
+}
+
+func (p *TalkProcessor) getGroupPrefixes(gid string) *groupPrefixes {
+	p.prefixes.Lock()
+	g, ok := p.prefixes.list[gid]
+	p.prefixes.Unlock()
+	if ok {
+		return g
+	}
+	g, err := p.loadPrefixes(gid)
+	if err != nil {
+		log.Println("error:", err.Error())
+		return &groupPrefixes{custom: map[cmdregistry.Family][]string{}}
+	}
+	p.prefixes.Lock()
+	p.prefixes.list[gid] = g
+	p.prefixes.Unlock()
+	return g
+}
+
+func (p *TalkProcessor) forgetPrefixes(gid string) {
+	p.prefixes.Lock()
+	delete(p.prefixes.list, gid)
+	p.prefixes.Unlock()
+}
+
+func (p *TalkProcessor) getPrefixes(gid string, family cmdregistry.Family) []string {
+	g := p.getGroupPrefixes(gid)
+	custom := g.custom[family]
+	prefixes := []string{}
+	if !g.replace || len(custom) == 0 {
+		prefixes = append(prefixes, familyPrefixes[family]...)
+	}
+	prefixes = append(prefixes, custom...)
+	sort.SliceStable(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+	return prefixes
+}
+
+func (p *TalkProcessor) displayPrefix(gid string, family cmdregistry.Family, language string) string {
+	g := p.getGroupPrefixes(gid)
+	if custom := g.custom[family]; g.replace && len(custom) > 0 {
+		return custom[0]
+	}
+	return i18n.T(language, familyPrefixKeys[family])
+}
+
+func (p *TalkProcessor) validatePrefix(gid string, family cmdregistry.Family, prefix string) (string, bool) {
+	if prefix == "" || utf8.RuneCountInString(prefix) > MAX_PREFIX_LENGTH {
+		return p.Utils.T(gid, "prefix.invalid", MAX_PREFIX_LENGTH), false
+	}
+	if strings.ContainsAny(prefix, "\"「」“”") || strings.IndexFunc(prefix, unicode.IsSpace) >= 0 {
+		return p.Utils.T(gid, "prefix.invalid", MAX_PREFIX_LENGTH), false
+	}
+	g := p.getGroupPrefixes(gid)
+	normalized := normalizePrefix(prefix)
+	for other := range familyColumns {
+		existing := append(append([]string{}, familyPrefixes[other]...), g.custom[other]...)
+		for _, e := range existing {
+			e = normalizePrefix(e)
+			if other == family {
+				if e == normalized {
+					return p.Utils.T(gid, "prefix.already", prefix), false
+				}
+				continue
+			}
+			if strings.HasPrefix(e, normalized) || strings.HasPrefix(normalized, e) {
+				return p.Utils.T(gid, "prefix.conflict", e), false
+			}
+		}
+	}
+	return "", true
+}
+
+func (p *TalkProcessor) resolvePrefixFamily(message *linethrift.Message, name string) (cmdregistry.Family, bool) {
+	family, ok := prefixFamilies[cmd.Resolve(name)]
+	if !ok {
+		language := p.Utils.GetLanguage(message.To)
+		p.Utils.ReplyLocalized(
+			p.Ctx, message, "prefix.family.invalid",
+			cmd.LocalName(language, cmd.PREFIXFAMILY_NORMAL),
+			cmd.LocalName(language, cmd.PREFIXFAMILY_SETTING),
+		)
+	}
+	return family, ok
+}
+
+func (p *TalkProcessor) AddPrefix(message *linethrift.Message, familyName string, prefix string) {
+	family, ok := p.resolvePrefixFamily(message, familyName)
+	if !ok {
+		return
+	}
+	if reply, ok := p.validatePrefix(message.To, family, prefix); !ok {
+		p.Utils.Reply(p.Ctx, message, reply)
+		return
+	}
+	_, err := p.DB.Exec(
+		`INSERT IGNORE INTO prefixes(gid, family, prefix) VALUES (?, ?, ?)`,
+		message.To, familyColumns[family], prefix,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	p.forgetPrefixes(message.To)
+	p.Utils.ReplyLocalized(p.Ctx, message, "prefix.added", prefix)
+}
+
+func (p *TalkProcessor) RemovePrefix(message *linethrift.Message, familyName string, prefix string) {
+	family, ok := p.resolvePrefixFamily(message, familyName)
+	if !ok {
+		return
+	}
+	result, err := p.DB.Exec(
+		`DELETE FROM prefixes WHERE gid = ? AND family = ? AND prefix = ?`,
+		message.To, familyColumns[family], prefix,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "prefix.notfound", prefix)
+		return
+	}
+	p.forgetPrefixes(message.To)
+	p.Utils.ReplyLocalized(p.Ctx, message, "prefix.removed", prefix)
+}
+
+func (p *TalkProcessor) SwitchPrefixReplace(message *linethrift.Message, isEnabledText string) {
+	isEnabled, ok := cmdregistry.ParseSwitch(isEnabledText)
+	if !ok {
+		err := &cmdregistry.ArgError{Arg: "arg.switch", Reason: "arg.error.switch"}
+		p.Utils.Reply(p.Ctx, message, err.Localize(p.Utils.GetLanguage(message.To)))
+		return
+	}
+	_, err := p.DB.Exec(
+		`UPDATE protections SET prefixreplace = ? WHERE id = ?`,
+		isEnabled, message.To,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	p.forgetPrefixes(message.To)
+	if isEnabled {
+		p.Utils.ReplyLocalized(p.Ctx, message, "prefix.replace.on")
+	} else {
+		p.Utils.ReplyLocalized(p.Ctx, message, "prefix.replace.off")
+	}
+}
+
+func (p *TalkProcessor) CheckPrefixes(message *linethrift.Message) {
+	p.Utils.ReplyLocalized(
+		p.Ctx, message, "prefix.list",
+		strings.Join(p.getPrefixes(message.To, cmdregistry.FAMILY_NORMAL), " "),
+		strings.Join(p.getPrefixes(message.To, cmdregistry.FAMILY_SETTING), " "),
+	)
+}
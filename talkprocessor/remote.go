@@ -0,0 +1,178 @@
+package talkprocessor
+
+import (
+	"log"
+	"strconv"
+	"sync"
+
+	"../cmdchecker"
+	cmd "../cmdconst"
+	"../cmdparser"
+	"../cmdregistry"
+	"../utils"
+	"github.com/mopeneko/linethrift"
+)
+
+type groupSelectionList struct {
+	sync.Mutex
+	list map[string]string
+}
+
+func (p *TalkProcessor) getManagedGroups(mid string) ([]string, error) {
+	rows, err := p.DB.Query(
+		`SELECT id FROM protections WHERE inviter = ? OR subadmin = ? ORDER BY id`,
+		mid, mid,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	gids := []string{}
+	for rows.Next() {
+		var gid string
+		if err := rows.Scan(&gid); err != nil {
+			return nil, err
+		}
+		gids = append(gids, gid)
+	}
+	return gids, rows.Err()
+}
+
+func (p *TalkProcessor) getGroupName(gid string) string {
+	group, err := p.Utils.Client[0].GetGroup(p.Ctx, gid)
+	if err != nil {
+		return p.Utils.T(gid, "group.unknown")
+	}
+	return group.Name
+}
+
+func (p *TalkProcessor) selectedGroup(mid string) (string, bool) {
+	p.selections.Lock()
+	defer p.selections.Unlock()
+	gid, ok := p.selections.list[mid]
+	return gid, ok
+}
+
+func (p *TalkProcessor) forgetSelection(mid string) {
+	p.selections.Lock()
+	delete(p.selections.list, mid)
+	p.selections.Unlock()
+}
+
+func (p *TalkProcessor) remoteMessage(message *linethrift.Message, gid string) *linethrift.Message {
+	remote := *message
+	remote.To = gid
+	remote.ToType = linethrift.MIDType_GROUP
+	remote.ContentMetadata = map[string]string{}
+	for key, value := range message.ContentMetadata {
+		remote.ContentMetadata[key] = value
+	}
+	remote.ContentMetadata[utils.REPLY_TO_METADATA] = message.From
+	return &remote
+}
+
+func (p *TalkProcessor) directCommand(name string) string {
+	return familyPrefixes[cmdregistry.FAMILY_NORMAL][0] + name
+}
+
+func (p *TalkProcessor) isDirectCommand(text string, prefix string) bool {
+	parsed, err := cmdparser.Parse(text, prefix)
+	if err != nil {
+		return false
+	}
+	_, ok := p.Registry.Lookup(cmdregistry.FAMILY_DIRECT, parsed.Name)
+	return ok
+}
+
+func (p *TalkProcessor) processDirect(message *linethrift.Message) {
+	gid, selected := p.selectedGroup(message.From)
+	if selected {
+		if ok, _ := p.Utils.HasGroupPermission(gid, message.From); !ok {
+			p.forgetSelection(message.From)
+			p.Utils.ReplyLocalized(p.Ctx, message, "remote.forbidden")
+			return
+		}
+	}
+
+	if message.ContentType == linethrift.ContentType_NONE {
+		// Direct and normal commands
+		if prefix, ok := cmdchecker.HasPrefixCommand(message.Text, familyPrefixes[cmdregistry.FAMILY_NORMAL]); ok {
+			if selected && !p.isDirectCommand(message.Text, prefix) {
+				p.dispatch(p.remoteMessage(message, gid), cmdregistry.FAMILY_NORMAL, prefix)
+			} else {
+				p.dispatch(message, cmdregistry.FAMILY_DIRECT, prefix)
+			}
+			return
+		}
+
+		// Setting commands
+		if prefix, ok := cmdchecker.HasPrefixCommand(message.Text, familyPrefixes[cmdregistry.FAMILY_SETTING]); ok {
+			if selected {
+				p.dispatch(p.remoteMessage(message, gid), cmdregistry.FAMILY_SETTING, prefix)
+			} else {
+				p.Utils.ReplyLocalized(p.Ctx, message, "remote.notselected", p.directCommand(cmd.DIRECT_GROUPS))
+			}
+			return
+		}
+	}
+	if p.processPendingInput(message.To, message) {
+		return
+	}
+	if selected {
+		p.processPendingInput(gid, p.remoteMessage(message, gid))
+	}
+}
+
+func (p *TalkProcessor) ListManagedGroups(message *linethrift.Message) {
+	gids, err := p.getManagedGroups(message.From)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if len(gids) == 0 {
+		p.Utils.ReplyLocalized(p.Ctx, message, "remote.none")
+		return
+	}
+	selected, _ := p.selectedGroup(message.From)
+	text := p.Utils.T(message.To, "remote.list")
+	for i, gid := range gids {
+		mark := ""
+		if gid == selected {
+			mark = " ◀"
+		}
+		text += "\n" + strconv.Itoa(i+1) + ". " + p.getGroupName(gid) + mark
+	}
+	text += "\n\n" + p.Utils.T(message.To, "remote.hint", p.directCommand(cmd.DIRECT_SELECT))
+	p.Utils.Reply(p.Ctx, message, text)
+}
+
+func (p *TalkProcessor) SelectGroup(message *linethrift.Message, numberText string) {
+	gids, err := p.getManagedGroups(message.From)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	number, _ := strconv.Atoi(numberText)
+	if number < 1 || number > len(gids) {
+		p.Utils.ReplyLocalized(p.Ctx, message, "remote.invalid", p.directCommand(cmd.DIRECT_GROUPS))
+		return
+	}
+	gid := gids[number-1]
+	p.selections.Lock()
+	p.selections.list[message.From] = gid
+	p.selections.Unlock()
+	p.Utils.ReplyLocalized(
+		p.Ctx, message, "remote.selected",
+		p.getGroupName(gid),
+		familyPrefixes[cmdregistry.FAMILY_NORMAL][0],
+		familyPrefixes[cmdregistry.FAMILY_SETTING][0],
+		p.directCommand(cmd.DIRECT_DESELECT),
+	)
+}
+
+func (p *TalkProcessor) DeselectGroup(message *linethrift.Message) {
+	p.forgetSelection(message.From)
+	p.Utils.ReplyLocalized(p.Ctx, message, "remote.deselected")
+}
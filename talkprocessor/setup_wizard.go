@@ -0,0 +1,203 @@
+package talkprocessor
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	cmd "../cmdconst"
+	"../cmdprocessor"
+	"../i18n"
+	"../pendinginput"
+)
+
+const SETUP_WIZARD_TIMEOUT = time.Minute * 10
+
+const (
+	SETUP_STEP_LANGUAGE = iota
+	SETUP_STEP_PROTECTION
+	SETUP_STEP_SUBADMIN
+	SETUP_STEP_DONE
+)
+
+var setupLanguages = map[string]string{
+	"1": i18n.LANG_JA,
+	"2": i18n.LANG_EN,
+}
+
+var setupPresets = map[string]string{
+	"1": cmd.PRESET_STANDARD,
+	"2": cmd.PRESET_STRICT,
+	"3": cmd.PRESET_OPEN,
+}
+
+var (
+	setupSkipTexts = []string{"スキップ", "skip"}
+	setupNoneTexts = []string{"なし", "none"}
+)
+
+func isSetupText(text string, candidates []string) bool {
+	for _, candidate := range candidates {
+		if strings.EqualFold(text, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *TalkProcessor) StartSetupWizard(gid string, inviter string) {
+	p.Utils.SendMessageWithRandomClient(
+		p.Ctx, gid,
+		p.Utils.T(gid, "wizard.start")+"\n\n"+p.setupQuestion(gid, SETUP_STEP_LANGUAGE),
+	)
+	p.askSetupWizard(gid, inviter, SETUP_STEP_LANGUAGE)
+}
+
+func (p *TalkProcessor) setupQuestion(gid string, step int) string {
+	switch step {
+	case SETUP_STEP_LANGUAGE:
+		return p.Utils.T(gid, "wizard.language")
+	case SETUP_STEP_PROTECTION:
+		language := p.Utils.GetLanguage(gid)
+		return i18n.T(
+			language, "wizard.protection",
+			cmd.LocalName(language, cmd.PRESET_STANDARD),
+			cmd.LocalName(language, cmd.PRESET_STRICT),
+			cmd.LocalName(language, cmd.PRESET_OPEN),
+		)
+	case SETUP_STEP_SUBADMIN:
+		return p.Utils.T(gid, "wizard.subadmin")
+	}
+	return ""
+}
+
+func (p *TalkProcessor) askSetupWizard(gid string, inviter string, step int) {
+	inputType := pendinginput.INPUT_TEXT
+	if step == SETUP_STEP_SUBADMIN {
+		inputType |= pendinginput.INPUT_CONTACT | pendinginput.INPUT_MENTION
+	}
+	p.CmdProcessor.Inputs.Wait(&pendinginput.Request{
+		Chat:    gid,
+		Mid:     inviter,
+		Type:    inputType,
+		Timeout: SETUP_WIZARD_TIMEOUT,
+		Handler: func(input *pendinginput.Input) {
+			p.answerSetupWizard(gid, inviter, step, input)
+		},
+		Accept: func(input *pendinginput.Input) bool {
+			return isSetupAnswer(step, input)
+		},
+		OnTimeout: func() {
+			p.Utils.SendLocalizedMessage(p.Ctx, gid, "wizard.timeout")
+		},
+		OnCancel: func() {
+			p.Utils.SendLocalizedMessage(p.Ctx, gid, "wizard.cancelled")
+		},
+	})
+}
+
+func isSetupAnswer(step int, input *pendinginput.Input) bool {
+	if input.Type != pendinginput.INPUT_TEXT {
+		return true
+	}
+	text := strings.ToLower(input.Text)
+	if isSetupText(text, setupSkipTexts) {
+		return true
+	}
+	switch step {
+	case SETUP_STEP_LANGUAGE:
+		_, ok := setupLanguages[text]
+		if !ok {
+			_, ok = i18n.Resolve(text)
+		}
+		return ok
+	case SETUP_STEP_PROTECTION:
+		_, ok := setupPresets[text]
+		return ok || cmdprocessor.IsPreset(text)
+	case SETUP_STEP_SUBADMIN:
+		return isSetupText(text, setupNoneTexts)
+	}
+	return false
+}
+
+func (p *TalkProcessor) answerSetupWizard(gid string, inviter string, step int, input *pendinginput.Input) {
+	var reply string
+	answered := true
+	if input.Type != pendinginput.INPUT_TEXT || !isSetupText(input.Text, setupSkipTexts) {
+		reply, answered = p.applySetupAnswer(gid, step, input)
+	}
+	if !answered {
+		p.Utils.SendMessageWithRandomClient(p.Ctx, gid, reply)
+		p.askSetupWizard(gid, inviter, step)
+		return
+	}
+
+	step++
+	if reply != "" {
+		reply += "\n\n"
+	}
+	if step == SETUP_STEP_DONE {
+		language := p.Utils.GetLanguage(gid)
+		reply += i18n.T(language, "wizard.done", i18n.T(language, "prefix.setting")+cmd.LocalName(language, cmd.SETTING_CHECK))
+	} else {
+		reply += p.setupQuestion(gid, step)
+		p.askSetupWizard(gid, inviter, step)
+	}
+	p.Utils.SendMessageWithRandomClient(p.Ctx, gid, reply)
+}
+
+func (p *TalkProcessor) applySetupAnswer(gid string, step int, input *pendinginput.Input) (string, bool) {
+	text := strings.ToLower(input.Text)
+	switch step {
+	case SETUP_STEP_LANGUAGE:
+		language, ok := setupLanguages[text]
+		if !ok {
+			language, ok = i18n.Resolve(text)
+		}
+		if !ok {
+			return p.Utils.T(gid, "wizard.language.invalid"), false
+		}
+		if err := p.Utils.SetLanguage(gid, language); err != nil {
+			log.Println("error:", err.Error())
+			return p.Utils.T(gid, "error.generic"), false
+		}
+		return "", true
+	case SETUP_STEP_PROTECTION:
+		preset, ok := setupPresets[text]
+		if !ok {
+			preset = text
+		}
+		result, ok := p.CmdProcessor.SetPreset(gid, preset)
+		if !ok {
+			return p.Utils.T(gid, "wizard.protection.invalid"), false
+		}
+		return result, true
+	case SETUP_STEP_SUBADMIN:
+		switch input.Type {
+		case pendinginput.INPUT_CONTACT:
+			return p.setSetupSubAdmin(gid, input.Mid)
+		case pendinginput.INPUT_MENTION:
+			if len(input.Mids) != 1 {
+				return p.Utils.T(gid, "subadmin.onlyone"), false
+			}
+			return p.setSetupSubAdmin(gid, input.Mids[0])
+		}
+		if isSetupText(text, setupNoneTexts) {
+			return "", true
+		}
+		return p.Utils.T(gid, "wizard.subadmin.invalid"), false
+	}
+	return "", false
+}
+
+func (p *TalkProcessor) setSetupSubAdmin(gid string, mid string) (string, bool) {
+	contact, err := p.Utils.Client[0].GetContact(p.Ctx, mid)
+	if err != nil {
+		return p.Utils.T(gid, "error.contact"), false
+	}
+	if err := p.CmdProcessor.SetSubAdmin(gid, mid); err != nil {
+		log.Println("error:", err.Error())
+		return p.Utils.T(gid, "error.generic"), false
+	}
+	return p.Utils.T(gid, "subadmin.changed", contact.DisplayName), true
+}
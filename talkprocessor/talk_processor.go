@@ -3,217 +3,167 @@ package talkprocessor
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	"../cmdchecker"
 	cmd "../cmdconst"
-	"../cmdparser"
 	"../cmdprocessor"
+	"../cmdregistry"
+	"../jobscheduler"
+	"../pendinginput"
+	"../ratelimit"
 	"../utils"
-	"github.com/google/uuid"
 	"github.com/mopeneko/linethrift"
 )
 
 type TalkProcessor struct {
-	Utils                *utils.Utils
-	DB                   *sql.DB
-	Ctx                  context.Context
-	Executed             []string
-	CmdProcessor         *cmdprocessor.CommandProcessor
-	StartProgramTime     time.Time
-	ChangeSubAdminSwitch map[string]bool
+	Utils            *utils.Utils
+	DB               *sql.DB
+	Ctx              context.Context
+	CmdProcessor     *cmdprocessor.CommandProcessor
+	StartProgramTime time.Time
+	Registry         *cmdregistry.Registry
+	recentSenders    *recentSenderList
+	prefixes         *prefixCache
+	selections       *groupSelectionList
+	UserLimiter      *ratelimit.Limiter
+	GroupLimiter     *ratelimit.Limiter
+	SuggestLimiter   *ratelimit.Limiter
+	RejectionLimiter *ratelimit.Limiter
+	FallbackLimiter  *ratelimit.Limiter
+	Jobs             *jobscheduler.Scheduler
 }
 
-const HELP_TEXT = "ここはモードセレクト！この場所から全てが始まるのですっ\n\nline://app/1559882908-RgxMO3P1"
+const (
+	DEFAULT_USER_COMMAND_LIMIT  = "3/10s"
+	DEFAULT_GROUP_COMMAND_LIMIT = "10/10s"
+	DEFAULT_SUGGESTION_LIMIT    = "2/1m"
+	DEFAULT_REJECTION_LIMIT     = "1/1h"
+	DEFAULT_FALLBACK_LIMIT      = "3/1h"
+)
 
 func Init(u *utils.Utils, db *sql.DB, ctx context.Context, startProgramTime time.Time) *TalkProcessor {
-	executed := []string{}
 	cmdp := cmdprocessor.Init(u, db, ctx, startProgramTime)
-	changeSubAdminSwitch := make(map[string]bool)
-	go func() {
-		time.Sleep(time.Hour * 1)
-		u.CleanGroups()
-	}()
+	recentSenders := &recentSenderList{senders: map[string]map[string]string{}, order: map[string][]string{}}
+	prefixes := &prefixCache{list: map[string]*groupPrefixes{}}
+	selections := &groupSelectionList{list: map[string]string{}}
+
+	userLimiter := newLimiter("USER_COMMAND_LIMIT", DEFAULT_USER_COMMAND_LIMIT)
+	groupLimiter := newLimiter("GROUP_COMMAND_LIMIT", DEFAULT_GROUP_COMMAND_LIMIT)
+	suggestLimiter := newLimiter("SUGGESTION_LIMIT", DEFAULT_SUGGESTION_LIMIT)
+	rejectionLimiter := newLimiter("REJECTION_NOTICE_LIMIT", DEFAULT_REJECTION_LIMIT)
+	fallbackLimiter := newLimiter("REJECTION_FALLBACK_LIMIT", DEFAULT_FALLBACK_LIMIT)
+
+	tp := &TalkProcessor{u, db, ctx, cmdp, startProgramTime, cmdregistry.New(), recentSenders, prefixes, selections, userLimiter, groupLimiter, suggestLimiter, rejectionLimiter, fallbackLimiter, newScheduler()}
+	tp.registerCommands()
+	tp.registerJobs()
+	return tp
+}
 
-	return &TalkProcessor{u, db, ctx, executed, cmdp, startProgramTime, changeSubAdminSwitch}
+func newLimiter(env string, fallback string) *ratelimit.Limiter {
+	if text := os.Getenv(env); text != "" {
+		limiter, err := ratelimit.Parse(text)
+		if err == nil {
+			return limiter
+		}
+		log.Printf("error: %s | %s", env, err.Error())
+	}
+	limiter, _ := ratelimit.Parse(fallback)
+	return limiter
 }
 
-func (p *TalkProcessor) ClearExecutedList() {
-	for {
-		time.Sleep(time.Second * 2)
-		p.Executed = nil
+func (p *TalkProcessor) allowCommand(message *linethrift.Message) bool {
+	if !p.UserLimiter.Allow(message.To + ":" + message.From) {
+		return false
 	}
+	if message.ToType == linethrift.MIDType_USER {
+		return true
+	}
+	if ok, _ := p.Utils.HasGroupPermission(message.To, message.From); ok {
+		return true
+	}
+	return p.GroupLimiter.Allow(message.To)
 }
 
-func contains(s []string, e string) bool {
-	for _, v := range s {
-		if e == v {
-			return true
+func (p *TalkProcessor) buildInput(message *linethrift.Message) *pendinginput.Input {
+	switch message.ContentType {
+	case linethrift.ContentType_NONE:
+		for family := range familyPrefixes {
+			if _, ok := cmdchecker.HasPrefixCommand(message.Text, p.getPrefixes(message.To, family)); ok {
+				return nil
+			}
+		}
+		if targets := p.getTargets(message); len(targets) > 0 {
+			return &pendinginput.Input{
+				Type:    pendinginput.INPUT_MENTION,
+				Message: message,
+				Mids:    targets,
+				Text:    strings.TrimSpace(stripMentions(message)),
+			}
+		}
+		return &pendinginput.Input{
+			Type:    pendinginput.INPUT_TEXT,
+			Message: message,
+			Text:    strings.TrimSpace(message.Text),
+		}
+	case linethrift.ContentType_CONTACT:
+		return &pendinginput.Input{
+			Type:    pendinginput.INPUT_CONTACT,
+			Message: message,
+			Mid:     message.ContentMetadata["mid"],
+		}
+	case linethrift.ContentType_IMAGE:
+		return &pendinginput.Input{
+			Type:    pendinginput.INPUT_IMAGE,
+			Message: message,
 		}
 	}
-	return false
+	return nil
+}
+
+func (p *TalkProcessor) processPendingInput(chat string, message *linethrift.Message) bool {
+	input := p.buildInput(message)
+	if input == nil {
+		return false
+	}
+	return p.CmdProcessor.Inputs.Process(chat, message.From, input)
 }
 
 func (p *TalkProcessor) Process(message *linethrift.Message) {
+	delete(message.ContentMetadata, utils.REPLY_TO_METADATA)
 	switch message.ToType {
 	case linethrift.MIDType_GROUP:
-		if !contains(p.Executed, message.To) {
-			switch message.ContentType {
-			case linethrift.ContentType_NONE:
-				// Normal commands
-				if prefix, ok := cmdchecker.HasPrefixCommand(message.Text, []string{"たまき:", "💙"}); ok {
-					commands := cmdparser.ParsePhrases(message.Text, prefix)
-					command := cmdparser.ParseCommand(commands)
-
-					if cmdchecker.IsNormalCommand(commands) {
-						flag := true
-
-						switch command {
-						case cmd.NORMAL_HELP:
-							p.Utils.SendMessageWithRandomClient(p.Ctx, message.To, HELP_TEXT)
-						case cmd.NORMAL_CHECKSTATUS:
-							p.CmdProcessor.SendStatus(message)
-						case cmd.NORMAL_CHECKPERMISSION:
-							p.CmdProcessor.CheckPermission(message)
-						case cmd.NORMAL_CHECKSPEED:
-							p.CmdProcessor.CheckSendSpeed(message)
-						default:
-							flag = false
-						}
-
-						if !flag {
-							if ok, _ := p.Utils.HasGroupPermission(message.To, message.From); ok {
-								flag = true
-								switch command {
-								case cmd.NORMAL_CHECKKICKERS:
-									p.CmdProcessor.CheckKickers(message)
-								case cmd.NORMAL_LEAVEBOTS:
-									p.CmdProcessor.LeaveBots(message)
-								default:
-									flag = false
-								}
-							}
-						}
-
-						if flag {
-							p.Executed = append(p.Executed, message.To)
-						}
-					}
-					return
-				} else
-
-				// Setting commands
-				if prefix, ok := cmdchecker.HasPrefixCommand(message.Text, []string{"設定:"}); ok {
-					commands := cmdparser.ParsePhrases(message.Text, prefix)
-					command := cmdparser.ParseCommand(commands)
-
-					flag := true
-
-					if cmdchecker.IsNormalCommand(commands) {
-						switch command {
-						case cmd.NORMAL_HELP:
-							p.Utils.SendMessageWithRandomClient(p.Ctx, message.To, HELP_TEXT)
-						case cmd.SETTING_CHECK:
-							p.CmdProcessor.CheckSetting(message)
-						default:
-							flag = false
-						}
-
-						if !flag {
-							if ok, _ := p.Utils.HasGroupPermission(message.To, message.From); ok {
-								flag = true
-								switch command {
-								case cmd.NORMAL_CHANGESUBADMIN:
-									p.CmdProcessor.ChangeSubAdmin(message, p.ChangeSubAdminSwitch)
-								default:
-									flag = false
-								}
-							}
-						}
-					} else {
-						flag = false
-					}
-
-					if ok, _ := p.Utils.HasGroupPermission(message.To, message.From); ok && !flag && cmdchecker.IsSettingCommand(commands) {
-						flag = true
-
-						isEnabledText := commands[2]
-
-						switch command {
-						case cmd.SETTING_NAME:
-							p.CmdProcessor.SwitchNameProtection(message, isEnabledText)
-						case cmd.SETTING_ICON:
-							p.CmdProcessor.SwitchIconProtection(message, isEnabledText)
-						case cmd.SETTING_URL:
-							p.CmdProcessor.SwitchURLProtection(message, isEnabledText)
-						case cmd.SETTING_INVITE:
-							p.CmdProcessor.SwitchInviteProtection(message, isEnabledText)
-						default:
-							flag = false
-						}
-					}
-
-					if flag {
-						p.Executed = append(p.Executed, message.To)
-					}
-
-					return
-				}
-
-			case linethrift.ContentType_CONTACT:
-				if isEnabled, ok := p.ChangeSubAdminSwitch[message.To]; ok {
-					if isEnabled {
-						if ok, _ := p.Utils.HasGroupPermission(message.To, message.From); ok {
-							defer func() {
-								p.ChangeSubAdminSwitch[message.To] = false
-							}()
-							mid := message.ContentMetadata["mid"]
-							contact, err := p.Utils.Client[0].GetContact(p.Ctx, mid)
-							if err != nil {
-								p.Utils.SendMessageWithRandomClient(
-									p.Ctx, message.To,
-									"エラーが発生しました💦\n連絡先をお確かめください💦💦",
-								)
-								return
-							}
-							_, err = p.DB.Exec(
-								`UPDATE protections SET subadmin = ? WHERE id = ?`,
-								mid, message.To,
-							)
-							if err != nil {
-								p.Utils.SendMessageWithRandomClient(
-									p.Ctx, message.To,
-									"エラーが発生しました💦\n連絡先をお確かめください💦💦",
-								)
-								log.Println("error:", err.Error())
-								return
-							}
-							p.Utils.SendMessageWithRandomClient(
-								p.Ctx, message.To,
-								fmt.Sprintf("%sをサブ管理者に設定しました🐶💙✨", contact.DisplayName),
-							)
-						}
-					}
-				}
+		p.recordSender(message)
+		if message.ContentType == linethrift.ContentType_NONE {
+			// Normal commands
+			if prefix, ok := cmdchecker.HasPrefixCommand(message.Text, p.getPrefixes(message.To, cmdregistry.FAMILY_NORMAL)); ok {
+				p.dispatch(message, cmdregistry.FAMILY_NORMAL, prefix)
+				return
+			} else
+
+			// Setting commands
+			if prefix, ok := cmdchecker.HasPrefixCommand(message.Text, p.getPrefixes(message.To, cmdregistry.FAMILY_SETTING)); ok {
+				p.dispatch(message, cmdregistry.FAMILY_SETTING, prefix)
+				return
 			}
 		}
+		p.processPendingInput(message.To, message)
 	case linethrift.MIDType_USER:
-		if message.From == "u82e0913834e04d1514f7a071ea38b3aa" {
-			if message.Text == "チケット発行" {
-				id := uuid.New().String()
-				_, err := p.DB.Exec(
-					`INSERT INTO tickets(uuid) VALUES(?)`,
-					id,
-				)
-				if err != nil {
-					log.Println("error:", err.Error())
+		if p.Utils.IsAdmin(message.From) && message.Text == cmd.DIRECT_ISSUETICKETS {
+			p.dispatch(message, cmdregistry.FAMILY_DIRECT, "")
+			return
+		}
+		if message.ContentType == linethrift.ContentType_NONE {
+			if id, ok := utils.ParseTicketCode(message.Text); ok {
+				if p.UserLimiter.Allow(message.To + ":" + message.From) {
+					p.RedeemTicket(message, id)
 				}
-				message.To = message.From
-				message.Text = fmt.Sprintf("RegiProtect:%s", id)
-				p.Utils.Client[0].SendMessage(p.Ctx, 0, message)
+				return
 			}
 		}
+		p.processDirect(message)
 	}
 }
@@ -0,0 +1,149 @@
+package talkprocessor
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	cmd "../cmdconst"
+	"../utils"
+	"github.com/mopeneko/linethrift"
+)
+
+const DEFAULT_TICKET_LIST_SIZE = 20
+
+var ticketKinds = map[string]utils.TicketKind{
+	cmd.TICKETKIND_WEEK:      utils.TICKET_WEEK,
+	cmd.TICKETKIND_MONTH:     utils.TICKET_MONTH,
+	cmd.TICKETKIND_PERMANENT: utils.TICKET_PERMANENT,
+	cmd.TICKETKIND_TRIAL:     utils.TICKET_TRIAL,
+}
+
+func (p *TalkProcessor) ticketKindName(language string, kind utils.TicketKind) string {
+	for name, k := range ticketKinds {
+		if k == kind {
+			return cmd.LocalName(language, name)
+		}
+	}
+	return string(kind)
+}
+
+func (p *TalkProcessor) IssueTickets(message *linethrift.Message, args []string) {
+	language := p.Utils.GetLanguage(message.To)
+	kind := utils.TICKET_MONTH
+	if len(args) > 0 {
+		k, ok := ticketKinds[cmd.Resolve(args[0])]
+		if !ok {
+			p.Utils.ReplyLocalized(
+				p.Ctx, message, "ticket.kind.invalid",
+				cmd.LocalName(language, cmd.TICKETKIND_WEEK),
+				cmd.LocalName(language, cmd.TICKETKIND_MONTH),
+				cmd.LocalName(language, cmd.TICKETKIND_PERMANENT),
+				cmd.LocalName(language, cmd.TICKETKIND_TRIAL),
+			)
+			return
+		}
+		kind = k
+	}
+	count := 1
+	if len(args) > 1 {
+		count, _ = strconv.Atoi(args[1])
+		if count < 1 || count > utils.MAX_TICKET_BATCH {
+			p.Utils.ReplyLocalized(p.Ctx, message, "ticket.count.invalid", utils.MAX_TICKET_BATCH)
+			return
+		}
+	}
+
+	codes, err := p.Utils.IssueTickets(kind, count, utils.TICKET_VALIDITY)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	if count == 1 {
+		p.Utils.Reply(p.Ctx, message, codes[0])
+		return
+	}
+	p.Utils.ReplyLocalized(
+		p.Ctx, message, "ticket.issued",
+		p.ticketKindName(language, kind), count, strings.Join(codes, "\n"),
+	)
+}
+
+func (p *TalkProcessor) ListTickets(message *linethrift.Message, args []string) {
+	limit := DEFAULT_TICKET_LIST_SIZE
+	if len(args) > 0 {
+		limit, _ = strconv.Atoi(args[0])
+		if limit < 1 {
+			limit = DEFAULT_TICKET_LIST_SIZE
+		}
+	}
+	tickets, err := p.Utils.ListTickets(limit)
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+	issued, redeemed, err := p.Utils.CountTickets()
+	if err != nil {
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+
+	language := p.Utils.GetLanguage(message.To)
+	text := p.Utils.T(message.To, "ticket.list", issued, redeemed)
+	for _, t := range tickets {
+		status := ""
+		switch {
+		case t.RedeemedBy.Valid:
+			contact, err := p.Utils.Client[0].GetContact(p.Ctx, t.RedeemedBy.String)
+			name := p.Utils.T(message.To, "account.deleted")
+			if err == nil {
+				name = contact.DisplayName
+			}
+			status = p.Utils.T(message.To, "ticket.status.redeemed", name, t.RedeemedAt.Time.Format("2006-01-02"))
+		case t.IsExpired():
+			status = p.Utils.T(message.To, "ticket.status.expired")
+		case t.Expires.Valid:
+			status = p.Utils.T(message.To, "ticket.status.unused", t.Expires.Time.Format("2006-01-02"))
+		default:
+			status = p.Utils.T(message.To, "ticket.status.unused", p.Utils.T(message.To, "common.none"))
+		}
+		text += "\n\n" + p.ticketKindName(language, t.Kind) + " " + t.Code + "\n  " + status
+	}
+	p.Utils.Reply(p.Ctx, message, text)
+}
+
+func (p *TalkProcessor) RedeemTicket(message *linethrift.Message, id string) {
+	kind, err := p.Utils.RedeemTicket(id, message.From)
+	switch err {
+	case nil:
+	case utils.ErrTicketNotFound:
+		p.Utils.ReplyLocalized(p.Ctx, message, "ticket.notfound")
+		return
+	case utils.ErrTicketUsed:
+		p.Utils.ReplyLocalized(p.Ctx, message, "ticket.used")
+		return
+	case utils.ErrTicketExpired:
+		p.Utils.ReplyLocalized(p.Ctx, message, "ticket.expired")
+		return
+	case utils.ErrTicketTrial:
+		p.Utils.ReplyLocalized(p.Ctx, message, "ticket.trial")
+		return
+	default:
+		log.Println("error:", err.Error())
+		p.Utils.ReplyLocalized(p.Ctx, message, "error.generic")
+		return
+	}
+
+	_, status, err := p.Utils.HasPermission(message.From)
+	if err != nil {
+		log.Println("error:", err.Error())
+	}
+	if status == "なし" || status == "" {
+		status = p.Utils.T(message.To, "common.none")
+	}
+	language := p.Utils.GetLanguage(message.To)
+	p.Utils.ReplyLocalized(p.Ctx, message, "ticket.redeemed", p.ticketKindName(language, kind), status)
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const CLEANUP_INTERVAL = time.Second * 2
+
+type CleanupGroup struct {
+	Gid     string
+	Inviter string
+	Expair  time.Time
+}
+
+type CleanupInvitation struct {
+	Client int
+	Gid    string
+}
+
+type CleanupPlan struct {
+	Groups      []CleanupGroup
+	Invitations []CleanupInvitation
+}
+
+func (plan *CleanupPlan) Owners() []string {
+	owners := []string{}
+	seen := map[string]bool{}
+	for _, group := range plan.Groups {
+		if !seen[group.Inviter] {
+			seen[group.Inviter] = true
+			owners = append(owners, group.Inviter)
+		}
+	}
+	return owners
+}
+
+func (p *Utils) PlanCleanup() (*CleanupPlan, error) {
+	rows, err := p.DB.Query(
+		`SELECT protections.id, protections.inviter, users.expair
+		FROM protections
+		JOIN users ON users.id = protections.inviter
+		WHERE users.expair < DATE_SUB(CURDATE(), INTERVAL ? DAY) AND protections.joined = TRUE
+		ORDER BY protections.inviter, protections.id`,
+		GraceDays(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	plan := &CleanupPlan{[]CleanupGroup{}, []CleanupInvitation{}}
+	for rows.Next() {
+		var group CleanupGroup
+		var expair mysql.NullTime
+		if err := rows.Scan(&group.Gid, &group.Inviter, &expair); err != nil {
+			return nil, err
+		}
+		group.Expair = expair.Time
+		plan.Groups = append(plan.Groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	for i, cl := range p.Client {
+		gids, err := cl.GetGroupIdsInvited(ctx)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		for _, gid := range gids {
+			plan.Invitations = append(plan.Invitations, CleanupInvitation{i, gid})
+		}
+	}
+	return plan, nil
+}
+
+func (p *Utils) isStillExpired(group CleanupGroup) (bool, error) {
+	var isExpired bool
+	err := p.DB.QueryRow(
+		`SELECT exists(
+			SELECT 1 FROM protections
+			JOIN users ON users.id = protections.inviter
+			WHERE protections.id = ? AND protections.inviter = ? AND protections.joined = TRUE
+			AND users.expair < DATE_SUB(CURDATE(), INTERVAL ? DAY)
+		)`,
+		group.Gid, group.Inviter, GraceDays(),
+	).Scan(&isExpired)
+	return isExpired, err
+}
+
+func (p *Utils) ExecuteCleanup(plan *CleanupPlan) int {
+	ctx := context.Background()
+	left := 0
+	for _, group := range plan.Groups {
+		isExpired, err := p.isStillExpired(group)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		if !isExpired {
+			continue
+		}
+		for _, cl := range p.Client {
+			cl.LeaveGroup(ctx, 0, group.Gid)
+			time.Sleep(CLEANUP_INTERVAL)
+		}
+		p.MarkGroupLeft(group.Gid)
+		left++
+	}
+	for _, invitation := range plan.Invitations {
+		p.Client[invitation.Client].RejectGroupInvitation(ctx, 0, invitation.Gid)
+		time.Sleep(CLEANUP_INTERVAL)
+	}
+	log.Printf("%d group left, %d group canceled\n", left, len(plan.Invitations))
+	return left
+}
+
+func (p *Utils) CleanGroups() error {
+	plan, err := p.PlanCleanup()
+	if err != nil {
+		return err
+	}
+	p.ExecuteCleanup(plan)
+	return nil
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	DEFAULT_REMINDER_DAYS = "7,3,1"
+	DEFAULT_GRACE_DAYS    = 3
+)
+
+func ReminderDays() []int {
+	text := os.Getenv("EXPIRY_REMINDER_DAYS")
+	if text == "" {
+		text = DEFAULT_REMINDER_DAYS
+	}
+	days := []int{}
+	for _, field := range strings.Split(text, ",") {
+		d, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || d < 1 {
+			log.Printf("error: EXPIRY_REMINDER_DAYS | %s", field)
+			continue
+		}
+		days = append(days, d)
+	}
+	sort.Ints(days)
+	return days
+}
+
+func GraceDays() int {
+	if text := os.Getenv("EXPIRY_GRACE_DAYS"); text != "" {
+		days, err := strconv.Atoi(text)
+		if err == nil && days >= 0 {
+			return days
+		}
+		log.Printf("error: EXPIRY_GRACE_DAYS | %s", text)
+	}
+	return DEFAULT_GRACE_DAYS
+}
+
+func (p *Utils) IsSuspended(gid string) (bool, error) {
+	var isSuspended bool
+	err := p.DB.QueryRow(
+		`SELECT exists(
+			SELECT 1 FROM protections
+			JOIN users ON users.id = protections.inviter
+			WHERE protections.id = ? AND users.expair < CURDATE()
+		)`,
+		gid,
+	).Scan(&isSuspended)
+	return isSuspended, err
+}
+
+func (p *Utils) markNotified(target string, expair time.Time, stage int) (bool, error) {
+	result, err := p.DB.Exec(
+		`INSERT IGNORE INTO expirynotices(target, expair, stage) VALUES (?, ?, ?)`,
+		target, expair, stage,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (p *Utils) SendExpiryReminders() error {
+	ctx := context.Background()
+	reminderDays := ReminderDays()
+	if len(reminderDays) > 0 {
+		if err := p.remindUsers(ctx, reminderDays); err != nil {
+			return err
+		}
+	}
+	return p.noticeExpiredGroups(ctx, GraceDays())
+}
+
+func (p *Utils) remindUsers(ctx context.Context, reminderDays []int) error {
+	rows, err := p.DB.Query(
+		`SELECT id, expair, DATEDIFF(expair, CURDATE())
+		FROM users
+		WHERE expair >= CURDATE() AND expair <= DATE_ADD(CURDATE(), INTERVAL ? DAY)`,
+		reminderDays[len(reminderDays)-1],
+	)
+	if err != nil {
+		return err
+	}
+	type reminder struct {
+		mid       string
+		expair    time.Time
+		remaining int
+	}
+	reminders := []reminder{}
+	for rows.Next() {
+		var r reminder
+		var expair mysql.NullTime
+		if err := rows.Scan(&r.mid, &expair, &r.remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		r.expair = expair.Time
+		reminders = append(reminders, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range reminders {
+		stage := 0
+		for _, d := range reminderDays {
+			if r.remaining <= d {
+				stage = d
+				break
+			}
+		}
+		ok, err := p.markNotified(r.mid, r.expair, stage)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		if !ok {
+			continue
+		}
+		p.Client[0].SendMessage(ctx, 0, p.GenerateTextMessage(
+			r.mid,
+			p.T(r.mid, "expiry.reminder", r.expair.Format("2006-01-02"), r.remaining),
+		))
+	}
+	return nil
+}
+
+func (p *Utils) noticeExpiredGroups(ctx context.Context, graceDays int) error {
+	rows, err := p.DB.Query(
+		`SELECT protections.id, users.expair
+		FROM protections
+		JOIN users ON users.id = protections.inviter
+		WHERE users.expair < CURDATE() AND users.expair >= DATE_SUB(CURDATE(), INTERVAL ? DAY)
+		AND protections.joined = TRUE`,
+		graceDays,
+	)
+	if err != nil {
+		return err
+	}
+	type notice struct {
+		gid    string
+		expair time.Time
+	}
+	notices := []notice{}
+	for rows.Next() {
+		var n notice
+		var expair mysql.NullTime
+		if err := rows.Scan(&n.gid, &expair); err != nil {
+			rows.Close()
+			return err
+		}
+		n.expair = expair.Time
+		notices = append(notices, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, n := range notices {
+		ok, err := p.markNotified(n.gid, n.expair, 0)
+		if err != nil {
+			log.Println("error:", err.Error())
+			continue
+		}
+		if !ok {
+			continue
+		}
+		leaveDate := n.expair.AddDate(0, 0, graceDays+1).Format("2006-01-02")
+		p.SendLocalizedMessage(ctx, n.gid, "expiry.notice", n.expair.Format("2006-01-02"), leaveDate)
+	}
+	return nil
+}
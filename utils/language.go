@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+
+	"../i18n"
+	"github.com/mopeneko/linethrift"
+)
+
+type languageCache struct {
+	sync.Mutex
+	list map[string]string
+}
+
+func (p *Utils) GetLanguage(chat string) string {
+	p.languages.Lock()
+	language, ok := p.languages.list[chat]
+	p.languages.Unlock()
+	if ok {
+		return language
+	}
+
+	var fetched sql.NullString
+	err := p.DB.QueryRow(
+		`SELECT language FROM protections WHERE id = ?`,
+		chat,
+	).Scan(&fetched)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println("error:", err.Error())
+		return i18n.DEFAULT_LANGUAGE
+	}
+	language = i18n.DEFAULT_LANGUAGE
+	if fetched.Valid && i18n.IsSupported(fetched.String) {
+		language = fetched.String
+	}
+
+	p.languages.Lock()
+	p.languages.list[chat] = language
+	p.languages.Unlock()
+	return language
+}
+
+func (p *Utils) SetLanguage(gid string, language string) error {
+	_, err := p.DB.Exec(
+		`UPDATE protections SET language = ? WHERE id = ?`,
+		language, gid,
+	)
+	if err != nil {
+		return err
+	}
+	p.languages.Lock()
+	p.languages.list[gid] = language
+	p.languages.Unlock()
+	return nil
+}
+
+func (p *Utils) T(chat string, key string, args ...interface{}) string {
+	return i18n.T(p.GetLanguage(chat), key, args...)
+}
+
+func (p *Utils) SendLocalizedMessage(ctx context.Context, to string, key string, args ...interface{}) {
+	p.SendMessageWithRandomClient(ctx, to, p.T(to, key, args...))
+}
+
+func (p *Utils) ReplyLocalized(ctx context.Context, message *linethrift.Message, key string, args ...interface{}) {
+	p.Reply(ctx, message, p.T(message.To, key, args...))
+}
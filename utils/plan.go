@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"database/sql"
+	"log"
+)
+
+type Feature string
+
+const (
+	FEATURE_NAMELOCK   Feature = "name"
+	FEATURE_ICONLOCK   Feature = "image"
+	FEATURE_URLLOCK    Feature = "url"
+	FEATURE_INVITELOCK Feature = "invite"
+	FEATURE_LOCKDOWN   Feature = "lockdown"
+	FEATURE_BAN        Feature = "ban"
+	FEATURE_SCHEDULE   Feature = "schedule"
+)
+
+const (
+	PLAN_BASIC   = "basic"
+	PLAN_PRO     = "pro"
+	PLAN_PREMIUM = "premium"
+	PLAN_TRIAL   = "trial"
+
+	DEFAULT_PLAN = PLAN_PREMIUM
+)
+
+type Plan struct {
+	ID        string
+	MaxGroups int
+	Features  []Feature
+}
+
+var PlanOrder = []string{PLAN_BASIC, PLAN_PRO, PLAN_PREMIUM}
+
+var Plans = map[string]*Plan{
+	PLAN_BASIC: {
+		PLAN_BASIC, 1,
+		[]Feature{FEATURE_NAMELOCK, FEATURE_URLLOCK, FEATURE_INVITELOCK},
+	},
+	PLAN_PRO: {
+		PLAN_PRO, 3,
+		[]Feature{FEATURE_NAMELOCK, FEATURE_ICONLOCK, FEATURE_URLLOCK, FEATURE_INVITELOCK, FEATURE_BAN, FEATURE_SCHEDULE},
+	},
+	PLAN_PREMIUM: {
+		PLAN_PREMIUM, 0,
+		[]Feature{FEATURE_NAMELOCK, FEATURE_ICONLOCK, FEATURE_URLLOCK, FEATURE_INVITELOCK, FEATURE_BAN, FEATURE_SCHEDULE, FEATURE_LOCKDOWN},
+	},
+	PLAN_TRIAL: {
+		PLAN_TRIAL, 1,
+		[]Feature{FEATURE_NAMELOCK, FEATURE_URLLOCK, FEATURE_INVITELOCK},
+	},
+}
+
+func (plan *Plan) Allows(feature Feature) bool {
+	for _, f := range plan.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (plan *Plan) AllowsGroups(count int) bool {
+	return plan.MaxGroups == 0 || count < plan.MaxGroups
+}
+
+func PlansWith(feature Feature) []string {
+	ids := []string{}
+	for _, id := range PlanOrder {
+		if Plans[id].Allows(feature) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func lookupPlan(id sql.NullString) *Plan {
+	if plan, ok := Plans[id.String]; id.Valid && ok {
+		return plan
+	}
+	return Plans[DEFAULT_PLAN]
+}
+
+func (p *Utils) GetPlan(mid string) (*Plan, error) {
+	var id sql.NullString
+	err := p.DB.QueryRow(
+		`SELECT plan FROM users WHERE id = ?`,
+		mid,
+	).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return lookupPlan(id), nil
+}
+
+func (p *Utils) GetGroupPlan(gid string) (*Plan, error) {
+	var id sql.NullString
+	err := p.DB.QueryRow(
+		`SELECT users.plan
+		FROM protections
+		JOIN users ON users.id = protections.inviter
+		WHERE protections.id = ?`,
+		gid,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return Plans[PLAN_BASIC], nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lookupPlan(id), nil
+}
+
+func (p *Utils) SetPlan(mid string, id string) (bool, error) {
+	var isContainsUser bool
+	err := p.DB.QueryRow(
+		`SELECT exists(SELECT 1 FROM users WHERE id = ?)`,
+		mid,
+	).Scan(&isContainsUser)
+	if err != nil || !isContainsUser {
+		return false, err
+	}
+	_, err = p.DB.Exec(
+		`UPDATE users SET plan = ? WHERE id = ?`,
+		id, mid,
+	)
+	return err == nil, err
+}
+
+func (p *Utils) CountProtectedGroups(mid string, exclude string) (int, error) {
+	var count int
+	err := p.DB.QueryRow(
+		`SELECT COUNT(*) FROM protections WHERE inviter = ? AND id != ? AND joined = TRUE`,
+		mid, exclude,
+	).Scan(&count)
+	return count, err
+}
+
+func (p *Utils) MarkGroupLeft(gid string) {
+	_, err := p.DB.Exec(
+		`UPDATE protections SET joined = FALSE WHERE id = ?`,
+		gid,
+	)
+	if err != nil {
+		log.Println("error:", err.Error())
+	}
+}
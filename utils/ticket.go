@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+type TicketKind string
+
+const (
+	TICKET_WEEK      TicketKind = "7d"
+	TICKET_MONTH     TicketKind = "30d"
+	TICKET_PERMANENT TicketKind = "permanent"
+	TICKET_TRIAL     TicketKind = "trial"
+)
+
+const (
+	TICKET_PREFIX    = "RegiProtect:"
+	TICKET_VALIDITY  = time.Hour * 24 * 90
+	MAX_TICKET_BATCH = 100
+)
+
+var TicketDurations = map[TicketKind]int{
+	TICKET_WEEK:      7,
+	TICKET_MONTH:     30,
+	TICKET_PERMANENT: 0,
+	TICKET_TRIAL:     TRIAL_DAYS,
+}
+
+var (
+	ErrTicketNotFound = errors.New("Ticket is not found.")
+	ErrTicketUsed     = errors.New("Ticket is already redeemed.")
+	ErrTicketExpired  = errors.New("Ticket is expired.")
+	ErrTicketTrial    = errors.New("Trial ticket is only for new users.")
+)
+
+type Ticket struct {
+	Code       string
+	Kind       TicketKind
+	Created    time.Time
+	Expires    mysql.NullTime
+	RedeemedBy sql.NullString
+	RedeemedAt mysql.NullTime
+}
+
+func (t *Ticket) IsExpired() bool {
+	return !t.RedeemedBy.Valid && t.Expires.Valid && time.Now().After(t.Expires.Time)
+}
+
+func ParseTicketCode(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if len(text) < len(TICKET_PREFIX) || !strings.EqualFold(text[:len(TICKET_PREFIX)], TICKET_PREFIX) {
+		return "", false
+	}
+	id, err := uuid.Parse(strings.TrimSpace(text[len(TICKET_PREFIX):]))
+	if err != nil {
+		return "", false
+	}
+	return id.String(), true
+}
+
+func (p *Utils) IssueTickets(kind TicketKind, count int, validity time.Duration) ([]string, error) {
+	if _, ok := TicketDurations[kind]; !ok {
+		return nil, errors.New("Ticket kind is wrong.")
+	}
+	if count < 1 || count > MAX_TICKET_BATCH {
+		return nil, errors.New("Ticket count is out of range.")
+	}
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	expires := time.Now().Add(validity)
+	codes := make([]string, count)
+	for i := range codes {
+		id := uuid.New().String()
+		_, err := tx.Exec(
+			`INSERT INTO tickets(uuid, kind, created, expires) VALUES (?, ?, NOW(), ?)`,
+			id, string(kind), expires,
+		)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = TICKET_PREFIX + id
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (p *Utils) RedeemTicket(id string, mid string) (TicketKind, error) {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var kindFetched sql.NullString
+	var expires mysql.NullTime
+	var redeemedBy sql.NullString
+	err = tx.QueryRow(
+		`SELECT kind, expires, redeemedby FROM tickets WHERE uuid = ? FOR UPDATE`,
+		id,
+	).Scan(&kindFetched, &expires, &redeemedBy)
+	if err == sql.ErrNoRows {
+		return "", ErrTicketNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if redeemedBy.Valid {
+		return "", ErrTicketUsed
+	}
+	if expires.Valid && time.Now().After(expires.Time) {
+		return "", ErrTicketExpired
+	}
+	kind := TICKET_MONTH
+	if _, ok := TicketDurations[TicketKind(kindFetched.String)]; ok {
+		kind = TicketKind(kindFetched.String)
+	}
+
+	if kind == TICKET_TRIAL {
+		var isContainsUser bool
+		err := tx.QueryRow(
+			`SELECT exists(SELECT 1 FROM users WHERE id = ?)`,
+			mid,
+		).Scan(&isContainsUser)
+		if err != nil {
+			return "", err
+		}
+		if isContainsUser {
+			return "", ErrTicketTrial
+		}
+		ok, err := insertTrial(tx, mid)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", ErrTicketTrial
+		}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE tickets SET redeemedby = ?, redeemedat = NOW() WHERE uuid = ?`,
+		mid, id,
+	)
+	if err != nil {
+		return "", err
+	}
+	if kind == TICKET_TRIAL {
+		return kind, tx.Commit()
+	}
+	if kind == TICKET_PERMANENT {
+		_, err = tx.Exec(grantPermanentQuery, mid)
+	} else {
+		days := TicketDurations[kind]
+		_, err = tx.Exec(extendPermissionQuery, mid, days, days)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err = tx.Exec(clearTrialPlanQuery, mid); err != nil {
+		return "", err
+	}
+	return kind, tx.Commit()
+}
+
+func (p *Utils) ListTickets(limit int) ([]*Ticket, error) {
+	rows, err := p.DB.Query(
+		`SELECT uuid, kind, created, expires, redeemedby, redeemedat
+		FROM tickets
+		ORDER BY created DESC
+		LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tickets := []*Ticket{}
+	for rows.Next() {
+		t := &Ticket{}
+		var id string
+		var kind sql.NullString
+		var created mysql.NullTime
+		if err := rows.Scan(&id, &kind, &created, &t.Expires, &t.RedeemedBy, &t.RedeemedAt); err != nil {
+			return nil, err
+		}
+		t.Code = TICKET_PREFIX + id
+		t.Kind = TICKET_MONTH
+		if kind.Valid {
+			t.Kind = TicketKind(kind.String)
+		}
+		t.Created = created.Time
+		tickets = append(tickets, t)
+	}
+	return tickets, rows.Err()
+}
+
+func (p *Utils) CountTickets() (int, int, error) {
+	var issued, redeemed int
+	err := p.DB.QueryRow(
+		`SELECT COUNT(*), COUNT(redeemedby) FROM tickets`,
+	).Scan(&issued, &redeemed)
+	return issued, redeemed, err
+}
@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeUser struct {
+	plan interface{}
+}
+
+type fakeState struct {
+	mu      sync.Mutex
+	tickets map[string]string
+	used    map[string]string
+	users   map[string]*fakeUser
+	trials  map[string]bool
+}
+
+var fakeStates = struct {
+	sync.Mutex
+	list map[string]*fakeState
+}{list: map[string]*fakeState{}}
+
+type fakeDriver struct{}
+
+func init() {
+	sql.Register("utilsfake", fakeDriver{})
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeStates.Lock()
+	defer fakeStates.Unlock()
+	return &fakeConn{fakeStates.list[name]}, nil
+}
+
+type fakeConn struct {
+	state *fakeState
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.state, query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *fakeConn) Commit() error             { return nil }
+func (c *fakeConn) Rollback() error           { return nil }
+
+type fakeStmt struct {
+	state *fakeState
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	st := s.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	mid, _ := args[0].(string)
+	switch {
+	case strings.HasPrefix(s.query, "INSERT IGNORE INTO trials"):
+		if st.trials[mid] {
+			return driver.RowsAffected(0), nil
+		}
+		st.trials[mid] = true
+	case strings.HasPrefix(s.query, "INSERT INTO users(id, expair, plan)"):
+		if _, ok := st.users[mid]; ok {
+			return nil, fmt.Errorf("duplicate user %s", mid)
+		}
+		st.users[mid] = &fakeUser{args[2]}
+	case strings.HasPrefix(s.query, "INSERT INTO users(id, expair)"):
+		if _, ok := st.users[mid]; !ok {
+			st.users[mid] = &fakeUser{nil}
+		}
+	case strings.HasPrefix(s.query, "UPDATE users SET plan = NULL"):
+		if user, ok := st.users[mid]; ok && user.plan == PLAN_TRIAL {
+			user.plan = nil
+		}
+	case strings.HasPrefix(s.query, "UPDATE tickets SET redeemedby"):
+		st.used[args[1].(string)] = mid
+	default:
+		return nil, fmt.Errorf("unexpected exec: %s", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	st := s.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	key, _ := args[0].(string)
+	switch {
+	case strings.HasPrefix(s.query, "SELECT kind, expires, redeemedby FROM tickets"):
+		kind, ok := st.tickets[key]
+		if !ok {
+			return &fakeRows{}, nil
+		}
+		var redeemedBy interface{}
+		if mid, ok := st.used[key]; ok {
+			redeemedBy = mid
+		}
+		return &fakeRows{[][]driver.Value{{kind, nil, redeemedBy}}}, nil
+	case strings.HasPrefix(s.query, "SELECT exists(SELECT 1 FROM users WHERE id = ?)"):
+		_, ok := st.users[key]
+		return &fakeRows{[][]driver.Value{{ok}}}, nil
+	case strings.HasPrefix(s.query, "SELECT plan FROM users"):
+		user, ok := st.users[key]
+		if !ok {
+			return &fakeRows{}, nil
+		}
+		return &fakeRows{[][]driver.Value{{user.plan}}}, nil
+	case strings.HasPrefix(s.query, "SELECT users.plan"):
+		return &fakeRows{}, nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", s.query)
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.values) == 0 {
+		return []string{"value"}
+	}
+	columns := make([]string, len(r.values[0]))
+	for i := range columns {
+		columns[i] = fmt.Sprintf("c%d", i)
+	}
+	return columns
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func newFakeUtils(t *testing.T, tickets map[string]string, users map[string]interface{}, trials []string) *Utils {
+	state := &fakeState{
+		tickets: tickets,
+		used:    map[string]string{},
+		users:   map[string]*fakeUser{},
+		trials:  map[string]bool{},
+	}
+	for mid, plan := range users {
+		state.users[mid] = &fakeUser{plan}
+	}
+	for _, mid := range trials {
+		state.trials[mid] = true
+	}
+	fakeStates.Lock()
+	fakeStates.list[t.Name()] = state
+	fakeStates.Unlock()
+	db, err := sql.Open("utilsfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &Utils{DB: db}
+}
+
+func TestRedeemTicketPlan(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		users  map[string]interface{}
+		trials []string
+		err    error
+		plan   string
+	}{
+		{"trial ticket for new user", string(TICKET_TRIAL), nil, nil, nil, PLAN_TRIAL},
+		{"trial ticket after trial", string(TICKET_TRIAL), nil, []string{"u1"}, ErrTicketTrial, DEFAULT_PLAN},
+		{"trial ticket for user", string(TICKET_TRIAL), map[string]interface{}{"u1": PLAN_BASIC}, nil, ErrTicketTrial, PLAN_BASIC},
+		{"month ticket for new user", string(TICKET_MONTH), nil, nil, nil, DEFAULT_PLAN},
+		{"month ticket ends trial", string(TICKET_MONTH), map[string]interface{}{"u1": PLAN_TRIAL}, []string{"u1"}, nil, DEFAULT_PLAN},
+		{"permanent ticket keeps plan", string(TICKET_PERMANENT), map[string]interface{}{"u1": PLAN_PRO}, nil, nil, PLAN_PRO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newFakeUtils(t, map[string]string{"ticket": tt.kind}, tt.users, tt.trials)
+			kind, err := u.RedeemTicket("ticket", "u1")
+			if err != tt.err {
+				t.Fatalf("RedeemTicket() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && string(kind) != tt.kind {
+				t.Errorf("RedeemTicket() = %q, want %q", kind, tt.kind)
+			}
+			plan, err := u.GetPlan("u1")
+			if err != nil {
+				t.Fatalf("GetPlan() returned error: %v", err)
+			}
+			if plan.ID != tt.plan {
+				t.Errorf("plan = %q, want %q", plan.ID, tt.plan)
+			}
+		})
+	}
+}
+
+func TestGetGroupPlanWithoutUser(t *testing.T) {
+	u := newFakeUtils(t, nil, nil, nil)
+	plan, err := u.GetGroupPlan("c1")
+	if err != nil {
+		t.Fatalf("GetGroupPlan() returned error: %v", err)
+	}
+	if plan.ID == DEFAULT_PLAN {
+		t.Errorf("GetGroupPlan() fell back to %q for a group without an inviter", plan.ID)
+	}
+}
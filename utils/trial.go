@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const TRIAL_DAYS = 3
+
+const clearTrialPlanQuery = `UPDATE users SET plan = NULL WHERE id = ? AND plan = '` + PLAN_TRIAL + `'`
+
+func (p *Utils) HasUsedTrial(mid string) (bool, error) {
+	var isUsed bool
+	err := p.DB.QueryRow(
+		`SELECT exists(SELECT 1 FROM trials WHERE mid = ?)`,
+		mid,
+	).Scan(&isUsed)
+	return isUsed, err
+}
+
+func insertTrial(tx *sql.Tx, mid string) (bool, error) {
+	result, err := tx.Exec(
+		`INSERT IGNORE INTO trials(mid, started) VALUES (?, NOW())`,
+		mid,
+	)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO users(id, expair, plan) VALUES (?, DATE_ADD(CURDATE(), INTERVAL ? DAY), ?)`,
+		mid, TRIAL_DAYS, PLAN_TRIAL,
+	)
+	return err == nil, err
+}
+
+func (p *Utils) StartTrial(mid string) (bool, time.Time, error) {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	var isContainsUser bool
+	err = tx.QueryRow(
+		`SELECT exists(SELECT 1 FROM users WHERE id = ?)`,
+		mid,
+	).Scan(&isContainsUser)
+	if err != nil || isContainsUser {
+		return false, time.Time{}, err
+	}
+	ok, err := insertTrial(tx, mid)
+	if err != nil || !ok {
+		return false, time.Time{}, err
+	}
+	var expair mysql.NullTime
+	err = tx.QueryRow(
+		`SELECT expair FROM users WHERE id = ?`,
+		mid,
+	).Scan(&expair)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	return true, expair.Time, tx.Commit()
+}
+
+func (p *Utils) CancelTrial(mid string) error {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`DELETE FROM users WHERE id = ? AND plan = '`+PLAN_TRIAL+`'`,
+		mid,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`DELETE FROM trials WHERE mid = ?`,
+		mid,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
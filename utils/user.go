@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"database/sql"
+	"log"
+)
+
+const (
+	grantPermanentQuery = `INSERT INTO users(id, expair) VALUES (?, NULL)
+		ON DUPLICATE KEY UPDATE expair = NULL`
+	grantPermissionQuery = `INSERT INTO users(id, expair) VALUES (?, DATE_ADD(CURDATE(), INTERVAL ? DAY))
+		ON DUPLICATE KEY UPDATE expair = VALUES(expair)`
+	extendPermissionQuery = `INSERT INTO users(id, expair) VALUES (?, DATE_ADD(CURDATE(), INTERVAL ? DAY))
+		ON DUPLICATE KEY UPDATE expair = IF(expair IS NULL, NULL, DATE_ADD(GREATEST(expair, CURDATE()), INTERVAL ? DAY))`
+)
+
+func (p *Utils) GrantPermission(mid string, days int) error {
+	var err error
+	if days == 0 {
+		_, err = p.DB.Exec(grantPermanentQuery, mid)
+	} else {
+		_, err = p.DB.Exec(grantPermissionQuery, mid, days)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = p.DB.Exec(clearTrialPlanQuery, mid)
+	return err
+}
+
+func (p *Utils) ExtendPermission(mid string, days int) error {
+	_, err := p.DB.Exec(extendPermissionQuery, mid, days, days)
+	return err
+}
+
+func (p *Utils) RevokePermission(mid string) (bool, error) {
+	result, err := p.DB.Exec(
+		`UPDATE users SET expair = DATE_SUB(CURDATE(), INTERVAL 1 DAY) WHERE id = ?`,
+		mid,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (p *Utils) GetInvitedGroups(mid string) ([]string, error) {
+	rows, err := p.DB.Query(
+		`SELECT id FROM protections WHERE inviter = ? ORDER BY id`,
+		mid,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	gids := []string{}
+	for rows.Next() {
+		var gid string
+		if err := rows.Scan(&gid); err != nil {
+			return nil, err
+		}
+		gids = append(gids, gid)
+	}
+	return gids, rows.Err()
+}
+
+func (p *Utils) IsAdmin(mid string) bool {
+	if mid == ADMIN_MID {
+		return true
+	}
+	var isAdmin bool
+	err := p.DB.QueryRow(
+		`SELECT exists(SELECT 1 FROM admins WHERE mid = ?)`,
+		mid,
+	).Scan(&isAdmin)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println("error:", err.Error())
+		return false
+	}
+	return isAdmin
+}
+
+func (p *Utils) AddAdmin(mid string) (bool, error) {
+	result, err := p.DB.Exec(
+		`INSERT IGNORE INTO admins(mid) VALUES (?)`,
+		mid,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (p *Utils) RemoveAdmin(mid string) (bool, error) {
+	result, err := p.DB.Exec(
+		`DELETE FROM admins WHERE mid = ?`,
+		mid,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (p *Utils) GetAdmins() ([]string, error) {
+	rows, err := p.DB.Query(`SELECT mid FROM admins ORDER BY mid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	mids := []string{ADMIN_MID}
+	for rows.Next() {
+		var mid string
+		if err := rows.Scan(&mid); err != nil {
+			return nil, err
+		}
+		if mid != ADMIN_MID {
+			mids = append(mids, mid)
+		}
+	}
+	return mids, rows.Err()
+}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"math"
 	"math/big"
 	"math/rand"
@@ -24,11 +23,17 @@ import (
 	"github.com/mopeneko/linethrift"
 )
 
+const (
+	REPLY_TO_METADATA = "REPLY_TO"
+	ADMIN_MID         = "u82e0913834e04d1514f7a071ea38b3aa"
+)
+
 type Utils struct {
 	Client     []*linethrift.TalkServiceClient
 	DB         *sql.DB
 	Mids       []string
 	httpClient *http.Client
+	languages  *languageCache
 }
 
 func Init(client []*linethrift.TalkServiceClient, db *sql.DB) *Utils {
@@ -38,13 +43,20 @@ func Init(client []*linethrift.TalkServiceClient, db *sql.DB) *Utils {
 	for i, cl := range client {
 		mids[i] = cl.AuthToken[:33]
 	}
-	return &Utils{client, db, mids, &http.Client{}}
+	return &Utils{client, db, mids, &http.Client{}, &languageCache{list: map[string]string{}}}
 }
 
 func (p *Utils) GetRandomClient() *linethrift.TalkServiceClient {
 	return p.Client[rand.Intn(len(p.Client))]
 }
 
+func (p *Utils) GetRandomKicker() *linethrift.TalkServiceClient {
+	if len(p.Client) < 2 {
+		return p.Client[0]
+	}
+	return p.Client[1+rand.Intn(len(p.Client)-1)]
+}
+
 func (p *Utils) GenerateTextMessage(to string, text string) *linethrift.Message {
 	message := linethrift.NewMessage()
 	message.To = to
@@ -85,43 +97,76 @@ func (p *Utils) HasPermission(mid string) (bool, string, error) {
 	return false, "", nil
 }
 
-func (p *Utils) CleanGroups() {
-	rows, err := p.DB.Query(
-		`SELECT id FROM protections WHERE inviter IN (SELECT id FROM users WHERE expair < CURDATE());`,
-	)
+func (p *Utils) HasGroupPermission(gid string, mid string) (bool, error) {
+	var hasPermission bool
+	err := p.DB.QueryRow(
+		`SELECT exists(SELECT 1 FROM protections WHERE id = ? AND (inviter = ? OR subadmin = ?))`,
+		gid, mid, mid,
+	).Scan(&hasPermission)
 	if err != nil {
-		log.Println("error:", err.Error())
-		return
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, err
+		}
 	}
-	defer rows.Close()
-	ctx := context.Background()
-	for rows.Next() {
-		var gid string
-		if err := rows.Scan(&gid); err != nil {
-			log.Println("error:", err.Error())
-			return
+	return hasPermission, nil
+}
+
+func (p *Utils) IsGroupInviter(gid string, mid string) (bool, error) {
+	var isInviter bool
+	err := p.DB.QueryRow(
+		`SELECT exists(SELECT 1 FROM protections WHERE id = ? AND inviter = ?)`,
+		gid, mid,
+	).Scan(&isInviter)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, err
 		}
-		for _, cl := range p.Client {
-			cl.LeaveGroup(ctx, 0, gid)
-			time.Sleep(time.Second * 2)
+	}
+	return isInviter, nil
+}
+
+func (p *Utils) IsTrustedInviter(gid string, mid string) (bool, error) {
+	var isTrusted bool
+	err := p.DB.QueryRow(
+		`SELECT exists(SELECT 1 FROM trustedinviters WHERE gid = ? AND mid = ?)`,
+		gid, mid,
+	).Scan(&isTrusted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, err
 		}
 	}
-	for _, cl := range p.Client {
-		gids, _ := cl.GetGroupIdsInvited(ctx)
-		for _, gid := range gids {
-			cl.RejectGroupInvitation(ctx, 0, gid)
-			time.Sleep(time.Second * 2)
+	return isTrusted, nil
+}
+
+func (p *Utils) IsBanned(gid string, mid string) (bool, error) {
+	var isBanned bool
+	err := p.DB.QueryRow(
+		`SELECT exists(SELECT 1 FROM bans WHERE gid = ? AND mid = ?)`,
+		gid, mid,
+	).Scan(&isBanned)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		} else {
+			return false, err
 		}
-		log.Printf("%d group canceled\n", len(gids))
 	}
+	return isBanned, nil
 }
 
-func (p *Utils) HasGroupPermission(gid string, mid string) (bool, error) {
-	var hasPermission bool
+func (p *Utils) IsProtectedMember(gid string, mid string) (bool, error) {
+	var isProtected bool
 	err := p.DB.QueryRow(
-		`SELECT exists(SELECT 1 FROM protections WHERE id = ? AND (inviter = ? OR subadmin = ?))`,
-		gid, mid, mid,
-	).Scan(&hasPermission)
+		`SELECT exists(SELECT 1 FROM protectedmembers WHERE gid = ? AND mid = ?)`,
+		gid, mid,
+	).Scan(&isProtected)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return false, nil
@@ -129,7 +174,7 @@ func (p *Utils) HasGroupPermission(gid string, mid string) (bool, error) {
 			return false, err
 		}
 	}
-	return hasPermission, nil
+	return isProtected, nil
 }
 
 func (p *Utils) IsBotMid(mid string) bool {
@@ -227,3 +272,29 @@ func (p *Utils) SendMessageWithRandomClient(ctx context.Context, to string, text
 		),
 	)
 }
+
+func (p *Utils) ReplyChat(message *linethrift.Message) string {
+	if to, ok := message.ContentMetadata[REPLY_TO_METADATA]; ok {
+		return to
+	}
+	if message.ToType == linethrift.MIDType_USER {
+		return message.From
+	}
+	return message.To
+}
+
+func (p *Utils) IsRemote(message *linethrift.Message) bool {
+	_, ok := message.ContentMetadata[REPLY_TO_METADATA]
+	return ok
+}
+
+func (p *Utils) ReplyClient(message *linethrift.Message) *linethrift.TalkServiceClient {
+	if message.ToType == linethrift.MIDType_USER || p.IsRemote(message) {
+		return p.Client[0]
+	}
+	return p.GetRandomClient()
+}
+
+func (p *Utils) Reply(ctx context.Context, message *linethrift.Message, text string) {
+	p.ReplyClient(message).SendMessage(ctx, 0, p.GenerateTextMessage(p.ReplyChat(message), text))
+}